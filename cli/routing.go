@@ -2,6 +2,7 @@ package cli
 
 import (
 	"errors"
+	"github.com/paddyforan/jarvis/jsonschema"
   "github.com/paddyforan/jarvis/parse"
 	"github.com/paddyforan/jarvis/spec"
   "io"
@@ -153,5 +154,8 @@ func serveSpec(args argMap) error {
 }
 
 func serveJSONSchema(args argMap) error {
-  return nil
+	if len(args["format"]) < 1 {
+		args["format"] = append(args["format"], "json")
+	}
+	return serve(jsonschema.Generate, args)
 }
@@ -0,0 +1,378 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/paddyforan/jarvis/parse"
+	"io"
+	"strings"
+)
+
+const schemaVersion = "http://json-schema.org/draft-04/schema#"
+
+var UnsupportedOutputFormatError = errors.New("Unsupported output format.")
+
+// Generate writes a JSON Schema document to output, containing a definition for each of the resources and for the error representations they use.
+func Generate(outputFormat string, output io.WriteCloser, resources []*parse.Resource) error {
+	defer output.Close()
+	if strings.ToLower(outputFormat) != "json" {
+		return UnsupportedOutputFormatError
+	}
+	definitions := map[string]interface{}{}
+	for _, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		definitions[resource.QualifiedID()] = ResourceSchema(*resource)
+		for _, shape := range resource.Shapes {
+			definitions[resource.QualifiedID()+"."+shape.ID] = ShapeSchema(shape)
+		}
+		definitions[ErrorDefinition(resource.GetErrorFormat())] = ErrorSchema(resource.GetErrorFormat())
+		for _, interaction := range resource.Interactions {
+			if interaction.AcceptMany && interaction.GetAtomicity() == parse.AtomicityPerItem {
+				definitions[resource.QualifiedID()+"."+parse.ResultsKey] = ResultsSchema(*resource)
+				break
+			}
+		}
+	}
+	schema := map[string]interface{}{
+		"$schema":     schemaVersion,
+		"definitions": definitions,
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer([]byte{})
+	err = json.Indent(buf, raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(output)
+	return err
+}
+
+// ResourceSchema builds the schema describing the representation of the passed resource.
+func ResourceSchema(r parse.Resource) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, property := range r.Properties {
+		properties[property.ID] = PropertySchema(property)
+	}
+	schema := map[string]interface{}{
+		"title":       r.Name,
+		"description": r.Description,
+		"type":        "object",
+		"properties":  properties,
+	}
+	if required := RequiredProperties(r.Properties); len(required) > 0 {
+		schema["required"] = required
+	}
+	if r.OneOf != nil {
+		schema["oneOf"] = VariantSchemas(r)
+	}
+	var conditions []interface{}
+	for _, property := range r.AllProperties() {
+		if property.When != nil {
+			conditions = append(conditions, ConditionSchema(r, property))
+		}
+	}
+	if len(conditions) > 0 {
+		schema["allOf"] = conditions
+	}
+	return schema
+}
+
+// VariantSchemas builds a schema for each value of the resource's discriminator, describing the properties of the variant it selects.
+// Each schema rules out the properties only other variants have, so exactly one of them matches.
+func VariantSchemas(r parse.Resource) []interface{} {
+	var discriminator parse.Property
+	for _, property := range r.Properties {
+		if property.ID == r.OneOf.Discriminator {
+			discriminator = property
+		}
+	}
+	var schemas []interface{}
+	for _, value := range discriminator.Values {
+		variant, _ := r.GetVariant(value)
+		properties := map[string]interface{}{
+			discriminator.ID: map[string]interface{}{"enum": []interface{}{value}},
+		}
+		own := map[string]bool{}
+		for _, property := range variant.Properties {
+			properties[property.ID] = PropertySchema(property)
+			own[property.ID] = true
+		}
+		schema := map[string]interface{}{
+			"properties": properties,
+		}
+		if variant.Description != "" {
+			schema["description"] = variant.Description
+		}
+		required := RequiredProperties(variant.Properties)
+		if discriminator.Default != value {
+			required = append([]string{discriminator.ID}, required...)
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		var others []interface{}
+		for _, other := range r.OneOf.Variants {
+			for _, property := range other.Properties {
+				if !own[property.ID] {
+					others = append(others, map[string]interface{}{"required": []string{property.ID}})
+					own[property.ID] = true
+				}
+			}
+		}
+		if len(others) > 0 {
+			schema["not"] = map[string]interface{}{"anyOf": others}
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+// ConditionSchema builds the schema that only allows the passed property when the property it depends on has one of the values its condition allows.
+// If the property is required, it must also be set whenever it's allowed.
+func ConditionSchema(r parse.Resource, p parse.Property) map[string]interface{} {
+	var other parse.Property
+	for _, property := range r.Properties {
+		if property.ID == p.When.Property {
+			other = property
+		}
+	}
+	var allowed, disallowed []interface{}
+	for _, value := range other.Values {
+		if p.When.Holds(value) {
+			allowed = append(allowed, value)
+		} else {
+			disallowed = append(disallowed, value)
+		}
+	}
+	allow := valuesSchema(other, allowed)
+	absent := map[string]interface{}{"not": map[string]interface{}{"required": []string{p.ID}}}
+	if !p.IsRequired() {
+		return map[string]interface{}{"anyOf": []interface{}{allow, absent}}
+	}
+	required, _ := allow["required"].([]string)
+	allow["required"] = append(required, p.ID)
+	return map[string]interface{}{"anyOf": []interface{}{allow, map[string]interface{}{
+		"allOf": []interface{}{valuesSchema(other, disallowed), absent},
+	}}}
+}
+
+// valuesSchema builds the schema matching objects whose value for the passed property is one of the passed values, taking its default into account when it's left out.
+func valuesSchema(p parse.Property, values []interface{}) map[string]interface{} {
+	if len(values) == 0 {
+		return map[string]interface{}{"not": map[string]interface{}{}} // nothing matches
+	}
+	schema := map[string]interface{}{
+		"properties": map[string]interface{}{
+			p.ID: map[string]interface{}{"enum": values},
+		},
+	}
+	defaulted := false
+	for _, value := range values {
+		defaulted = defaulted || (p.Default != nil && value == p.Default)
+	}
+	if !defaulted {
+		schema["required"] = []string{p.ID}
+	}
+	return schema
+}
+
+// ShapeSchema builds the schema describing the passed ad-hoc response shape.
+func ShapeSchema(s parse.Shape) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, property := range s.Properties {
+		properties[property.ID] = PropertySchema(property)
+	}
+	schema := map[string]interface{}{
+		"description": s.Description,
+		"type":        "object",
+		"properties":  properties,
+	}
+	if required := RequiredProperties(s.Properties); len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// RequiredProperties returns the IDs of the passed properties that must always be set.
+func RequiredProperties(properties []parse.Property) []string {
+	var required []string
+	for _, property := range properties {
+		if property.IsRequired() && property.When == nil { // conditional properties are only required when they're allowed
+			required = append(required, property.ID)
+		}
+	}
+	return required
+}
+
+// PropertySchema builds the schema describing the values the passed property accepts.
+func PropertySchema(p parse.Property) map[string]interface{} {
+	schema := map[string]interface{}{
+		"description": p.Description,
+	}
+	t := strings.ToLower(p.Type)
+	switch t {
+	case "string", "pointer":
+		schema["type"] = "string"
+	case "bytes":
+		schema["type"] = "string"
+		schema["media"] = map[string]interface{}{"binaryEncoding": "base64"}
+	case "datetime":
+		schema["type"] = "string"
+		schema["format"] = "date-time"
+	case "duration", "int":
+		schema["type"] = "integer"
+	case "float":
+		schema["type"] = "number"
+	case "boolean", "array", "object":
+		schema["type"] = t
+	case "map":
+		schema["type"] = "object"
+	}
+	switch t {
+	case "string", "bytes":
+		if p.MinLength != 0 {
+			schema["minLength"] = p.MinLength
+		}
+		if p.MaxLength != 0 {
+			schema["maxLength"] = p.MaxLength
+		}
+	case "array":
+		if p.MinLength != 0 {
+			schema["minItems"] = p.MinLength
+		}
+		if p.MaxLength != 0 {
+			schema["maxItems"] = p.MaxLength
+		}
+		if p.UniqueItems {
+			schema["uniqueItems"] = true
+		}
+	case "map":
+		if p.MaxKeys != 0 {
+			schema["maxProperties"] = p.MaxKeys
+		}
+		values := PropertySchema(parse.Property{Type: p.ValueType})
+		if p.KeyPattern != "" {
+			schema["patternProperties"] = map[string]interface{}{p.KeyPattern: values}
+			schema["additionalProperties"] = false
+		} else {
+			schema["additionalProperties"] = values
+		}
+	case "duration", "int", "float":
+		if p.Minimum != nil {
+			schema["minimum"] = *p.Minimum
+			if p.ExclusiveMinimum {
+				schema["exclusiveMinimum"] = true
+			}
+		}
+		if p.Maximum != nil {
+			schema["maximum"] = *p.Maximum
+			if p.ExclusiveMaximum {
+				schema["exclusiveMaximum"] = true
+			}
+		}
+		if p.MultipleOf != 0 {
+			schema["multipleOf"] = p.MultipleOf
+		}
+	}
+	if p.Nullable {
+		if jsonType, ok := schema["type"]; ok {
+			schema["type"] = []interface{}{jsonType, "null"}
+		}
+	}
+	if t == "array" && p.ValueType != "" {
+		schema["items"] = PropertySchema(parse.Property{Type: p.ValueType})
+	}
+	if len(p.Values) > 0 {
+		enum := append([]interface{}{}, p.Values...)
+		if p.Nullable {
+			enum = append(enum, nil) // enum is checked on its own, so null has to be listed for the nullable type to allow it
+		}
+		schema["enum"] = enum
+	}
+	if p.Default != nil {
+		schema["default"] = p.Default
+	}
+	return schema
+}
+
+// ResultsSchema builds the schema describing the results per_item interactions against the passed resource return.
+func ResultsSchema(r parse.Resource) map[string]interface{} {
+	result := map[string]interface{}{
+		"index":  map[string]interface{}{"type": "integer", "description": "The position of the resource in the request."},
+		"status": map[string]interface{}{"type": "integer", "description": "The HTTP status code the resource succeeded or failed with."},
+		r.ID:     map[string]interface{}{"$ref": "#/definitions/" + r.QualifiedID()},
+	}
+	if r.GetErrorFormat() == parse.ErrorFormatProblem {
+		result["error"] = map[string]interface{}{"$ref": "#/definitions/" + ErrorDefinition(r.GetErrorFormat())}
+	} else {
+		result["errors"] = map[string]interface{}{"$ref": "#/definitions/" + ErrorDefinition(r.GetErrorFormat()) + "/properties/errors"}
+	}
+	return map[string]interface{}{
+		"title":    r.Name + " Results",
+		"type":     "object",
+		"required": []string{parse.ResultsKey},
+		"properties": map[string]interface{}{
+			parse.ResultsKey: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":       "object",
+					"required":   []string{"index", "status"},
+					"properties": result,
+				},
+			},
+		},
+	}
+}
+
+// ErrorDefinition returns the name of the definition describing the specified error format.
+func ErrorDefinition(errorFormat string) string {
+	if errorFormat == parse.ErrorFormatProblem {
+		return "problem"
+	}
+	return "errors"
+}
+
+// ErrorSchema builds the schema describing the error representation for the specified error format.
+func ErrorSchema(errorFormat string) map[string]interface{} {
+	fields := map[string]interface{}{
+		"code":    map[string]interface{}{"type": "string", "description": "A machine-readable identifier for the error."},
+		"status":  map[string]interface{}{"type": "integer", "description": "The HTTP status code the error was returned with."},
+		"pointer": map[string]interface{}{"type": "string", "format": "json-pointer", "description": "A JSON Pointer to the field in the request body that caused the error."},
+		"param":   map[string]interface{}{"type": "string", "description": "The URL parameter that caused the error."},
+	}
+	if errorFormat == parse.ErrorFormatProblem {
+		fields["type"] = map[string]interface{}{"type": "string", "format": "uri", "description": "A URI identifying the error."}
+		fields["title"] = map[string]interface{}{"type": "string", "description": "A human-friendly description of the error."}
+		fields["detail"] = map[string]interface{}{"type": "string", "description": "The action necessary to resolve the error."}
+		fields["instance"] = map[string]interface{}{"type": "string", "format": "uri", "description": "A URI identifying this occurrence of the error."}
+		return map[string]interface{}{
+			"title":      "Problem Details",
+			"type":       "object",
+			"required":   []string{"type", "title", "status", "code"},
+			"properties": fields,
+		}
+	}
+	fields["message"] = map[string]interface{}{"type": "string", "description": "A human-friendly description of the error."}
+	fields["action"] = map[string]interface{}{"type": "string", "description": "The action necessary to resolve the error."}
+	return map[string]interface{}{
+		"title":    "Errors",
+		"type":     "object",
+		"required": []string{"errors"},
+		"properties": map[string]interface{}{
+			"errors": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":       "object",
+					"required":   []string{"code", "status", "message"},
+					"properties": fields,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"github.com/paddyforan/jarvis/parse"
+	"reflect"
+	"testing"
+)
+
+func optional() *bool {
+	b := false
+	return &b
+}
+
+func TestRequiredProperties(t *testing.T) {
+	properties := []parse.Property{
+		{ID: "name", Type: "string"},
+		{ID: "size", Type: "int", Default: 10},
+		{ID: "label", Type: "string", Required: optional()},
+		{ID: "url", Type: "string", When: &parse.Condition{Property: "push_type", In: []interface{}{"push"}}},
+	}
+	required := RequiredProperties(properties)
+	if !reflect.DeepEqual(required, []string{"name"}) {
+		t.Errorf("Expected only name to be required, got %v.", required)
+	}
+}
+
+func TestNullableProperty(t *testing.T) {
+	schema := PropertySchema(parse.Property{ID: "error", Type: "string", Nullable: true, Values: []interface{}{"timeout", "refused"}})
+	if !reflect.DeepEqual(schema["type"], []interface{}{"string", "null"}) {
+		t.Errorf("Expected a nullable string type, got %v.", schema["type"])
+	}
+	if !reflect.DeepEqual(schema["enum"], []interface{}{"timeout", "refused", nil}) {
+		t.Errorf("Expected the enum to allow null, got %v.", schema["enum"])
+	}
+	schema = PropertySchema(parse.Property{ID: "error", Type: "string", Values: []interface{}{"timeout", "refused"}})
+	if schema["type"] != "string" || !reflect.DeepEqual(schema["enum"], []interface{}{"timeout", "refused"}) {
+		t.Errorf("Expected a property that isn't nullable not to allow null, got %v.", schema)
+	}
+}
+
+func TestVariantSchemas(t *testing.T) {
+	queue := parse.Resource{
+		ID: "queue",
+		Properties: []parse.Property{
+			{ID: "push_type", Type: "string", Default: "pull", Values: []interface{}{"pull", "push"}},
+			{ID: "retries", Type: "int", Required: optional(), When: &parse.Condition{Property: "push_type", NotIn: []interface{}{"pull"}}},
+		},
+		OneOf: &parse.OneOf{Discriminator: "push_type", Variants: []parse.Variant{
+			{Value: "pull"},
+			{Value: "push", Properties: []parse.Property{{ID: "url", Type: "string"}}},
+		}},
+	}
+	schema := ResourceSchema(queue)
+	variants, ok := schema["oneOf"].([]interface{})
+	if !ok || len(variants) != 2 {
+		t.Fatalf("Expected a oneOf with a schema for each variant, got %v.", schema["oneOf"])
+	}
+	pull := variants[0].(map[string]interface{})
+	if _, ok := pull["required"]; ok {
+		t.Errorf("Expected the default variant not to require push_type, got %v.", pull["required"])
+	}
+	if !reflect.DeepEqual(pull["not"], map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"required": []string{"url"}}}}) {
+		t.Errorf("Expected the pull variant to rule out url, got %v.", pull["not"])
+	}
+	push := variants[1].(map[string]interface{})
+	if !reflect.DeepEqual(push["required"], []string{"push_type", "url"}) {
+		t.Errorf("Expected the push variant to require push_type and url, got %v.", push["required"])
+	}
+	conditions, ok := schema["allOf"].([]interface{})
+	if !ok || len(conditions) != 1 {
+		t.Fatalf("Expected an allOf with the retries condition, got %v.", schema["allOf"])
+	}
+	expected := map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{
+			"properties": map[string]interface{}{"push_type": map[string]interface{}{"enum": []interface{}{"push"}}},
+			"required":   []string{"push_type"},
+		},
+		map[string]interface{}{"not": map[string]interface{}{"required": []string{"retries"}}},
+	}}
+	if !reflect.DeepEqual(conditions[0], expected) {
+		t.Errorf("Expected retries to be allowed only when push_type isn't pull, got %v.", conditions[0])
+	}
+}
+
+func TestMapSchema(t *testing.T) {
+	schema := PropertySchema(parse.Property{ID: "metadata", Type: "map", ValueType: "string", MaxKeys: 16, KeyPattern: "^[a-z_]+$"})
+	values := map[string]interface{}{"description": "", "type": "string"}
+	if schema["type"] != "object" || schema["maxProperties"] != 16 {
+		t.Errorf("Expected an object with at most 16 properties, got %v.", schema)
+	}
+	if !reflect.DeepEqual(schema["patternProperties"], map[string]interface{}{"^[a-z_]+$": values}) {
+		t.Errorf("Expected keys to match the key_pattern, got %v.", schema["patternProperties"])
+	}
+	if schema["additionalProperties"] != false {
+		t.Errorf("Expected keys that don't match the key_pattern to be rejected, got %v.", schema["additionalProperties"])
+	}
+	schema = PropertySchema(parse.Property{ID: "metadata", Type: "map", ValueType: "string"})
+	if _, ok := schema["patternProperties"]; ok {
+		t.Errorf("Expected no patternProperties without a key_pattern, got %v.", schema["patternProperties"])
+	}
+	if _, ok := schema["maxProperties"]; ok {
+		t.Errorf("Expected no maxProperties without max_keys, got %v.", schema["maxProperties"])
+	}
+	if !reflect.DeepEqual(schema["additionalProperties"], values) {
+		t.Errorf("Expected every key to hold a string, got %v.", schema["additionalProperties"])
+	}
+}
@@ -0,0 +1,105 @@
+package parse
+
+import (
+	"errors"
+	"io/ioutil"
+	"launchpad.net/goyaml"
+	"os"
+)
+
+// APIFile is the name of the file, in each API's directory, that describes the API.
+const APIFile = "api.yml"
+
+// An API is a collection of resources, defined by the resource files in a single directory. It is described by the optional api.yml in that directory.
+type API struct {
+	ID          string           `yaml:"-"` // The path of the API's directory, relative to the root
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Version     string           `yaml:"version,omitempty"`
+	BaseURL     string           `yaml:"base_url,omitempty"` // The URL all of the API's paths are relative to
+	Contact     *Contact         `yaml:"contact,omitempty"`
+	ErrorFormat string           `yaml:"error_format,omitempty"` // The representation errors are returned in. Acceptable values: jarvis, problem
+	Errors      []Error          `yaml:"errors,omitempty"`       // Errors any of the API's interactions can return
+	Security    []SecurityScheme `yaml:"security,omitempty"`     // The ways clients can authenticate with the API
+	Traits      []Trait          `yaml:"traits,omitempty"`       // Sets of properties and params the API's resources can include
+	Resources   []*Resource      `yaml:"-"`
+}
+
+// A SecurityScheme is the definition of a way clients can authenticate with an API.
+type SecurityScheme struct {
+	ID               string  `yaml:"id"`
+	Type             string  `yaml:"type"` // Acceptable values: bearer, oauth2, api_key
+	Description      string  `yaml:"description"`
+	In               string  `yaml:"in,omitempty"`                // Where an api_key is passed. Acceptable values: header, query
+	Name             string  `yaml:"name,omitempty"`              // The name of the header or query param an api_key is passed in
+	Scopes           []Scope `yaml:"scopes,omitempty"`            // The scopes an oauth2 token can be granted
+	CredentialsError string  `yaml:"credentials_error,omitempty"` // The code of the API error returned when credentials are missing or invalid
+	ScopeError       string  `yaml:"scope_error,omitempty"`       // The code of the API error returned when credentials lack a required scope
+}
+
+const (
+	SecurityBearer = "bearer"
+	SecurityOAuth2 = "oauth2"
+	SecurityAPIKey = "api_key"
+)
+
+// A Scope is a permission an OAuth2 token can be granted.
+type Scope struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+}
+
+// A Contact is the information about who to contact about an API.
+type Contact struct {
+	Name  string `yaml:"name,omitempty"`
+	Email string `yaml:"email,omitempty"`
+	URL   string `yaml:"url,omitempty"`
+}
+
+// ParseAPIFile will read the specified api.yml file and parse it into an API, which is then returned.
+func ParseAPIFile(path string) (API, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return API{}, err
+	}
+	var api API
+	err = goyaml.Unmarshal(content, &api)
+	if err != nil {
+		return API{}, err
+	}
+	switch api.ErrorFormat {
+	case "", ErrorFormatJarvis, ErrorFormatProblem:
+	default:
+		return API{}, errors.New("Unknown error_format: " + api.ErrorFormat)
+	}
+	return api, nil
+}
+
+// parseAPI reads the api.yml in the API's directory, if there is one. APIs without an api.yml, or whose api.yml has no name, are named after their directory.
+func parseAPI(root, path string) (*API, error) {
+	var api API
+	_, err := os.Stat(root + path + string(os.PathSeparator) + APIFile)
+	if err == nil {
+		api, err = ParseAPIFile(root + path + string(os.PathSeparator) + APIFile)
+		if err != nil {
+			return nil, errors.New("Error parsing " + path + "/" + APIFile + ": " + err.Error())
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if api.Name == "" {
+		api.Name = path
+	}
+	api.ID = path
+	return &api, nil
+}
+
+// GetError is a helper function that returns the error in the API's error catalog with the passed in code.
+func (api API) GetError(code string) (Error, bool) {
+	for _, e := range api.Errors {
+		if e.Code == code {
+			return e, true
+		}
+	}
+	return Error{}, false
+}
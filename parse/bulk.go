@@ -0,0 +1,45 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	AtomicityAtomic  = "atomic"   // If any of the resources fails, none of them are changed and the error is returned
+	AtomicityPerItem = "per_item" // Each resource succeeds or fails on its own, and a result is returned for each
+)
+
+// ResultsKey is the key the results of a per_item interaction are returned under.
+const ResultsKey = "results"
+
+// GetAtomicity is a helper function that returns how the interaction handles some of the resources it accepts failing, defaulting to atomic.
+func (i Interaction) GetAtomicity() string {
+	if i.Atomicity == "" {
+		return AtomicityAtomic
+	}
+	return strings.ToLower(i.Atomicity)
+}
+
+func validateAtomicity(interaction Interaction) error {
+	switch strings.ToLower(interaction.Atomicity) {
+	case "":
+	case AtomicityAtomic, AtomicityPerItem:
+		if !interaction.AcceptMany {
+			return errors.New("Interaction " + interaction.ID + " declares its atomicity, but only accept_many interactions can.")
+		}
+	default:
+		return errors.New("Interaction " + interaction.ID + " has an unknown atomicity: " + interaction.Atomicity)
+	}
+	if interaction.Response == nil {
+		return nil
+	}
+	returns := interaction.Response.Returns
+	if interaction.GetAtomicity() == AtomicityPerItem && interaction.AcceptMany && returns != "" && returns != ReturnsResults {
+		return errors.New("Interaction " + interaction.ID + " is per_item, so it must return results.")
+	}
+	if returns == ReturnsResults && (!interaction.AcceptMany || interaction.GetAtomicity() != AtomicityPerItem) {
+		return errors.New("Interaction " + interaction.ID + " returns results, but only per_item interactions can.")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package parse
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A Callback is the definition of an HTTP request the API sends to a URL held by the resource when an interaction is performed.
+type Callback struct {
+	ID              string    `yaml:"id"`
+	Name            string    `yaml:"name"`
+	Description     string    `yaml:"description"`
+	Trigger         string    `yaml:"trigger"` // The interaction that triggers the callback, in the form "{API ID}/{RESOURCE ID}#{INTERACTION ID}"
+	TriggerResource *Resource `yaml:"-"`
+	URL             string    `yaml:"url"`     // The ID of the property holding the URL the callback is POSTed to
+	PayloadString   string    `yaml:"payload"` // The resource sent as the body of the callback, in the form "{API ID}/{RESOURCE ID}"
+	Payload         *Resource `yaml:"-"`
+	Acknowledge     []int     `yaml:"acknowledge,omitempty"` // The statuses the receiver responds with to acknowledge the callback. Defaults to 200
+	Retry           *Retry    `yaml:"retry,omitempty"`       // How unacknowledged callbacks are retried
+	RetryResource   *Resource `yaml:"-"`                     // The resource holding the retry properties
+}
+
+// A Retry is the definition of how unacknowledged callbacks are retried, tied to the properties that configure it.
+type Retry struct {
+	Attempts string `yaml:"attempts"` // The ID of the int property holding the number of times the callback is retried
+	Delay    string `yaml:"delay"`    // The ID of the duration property holding the time between retries
+}
+
+// GetAcknowledge is a helper function that returns the statuses that acknowledge the callback, defaulting to 200.
+func (c Callback) GetAcknowledge() []int {
+	if len(c.Acknowledge) == 0 {
+		return []int{200}
+	}
+	return c.Acknowledge
+}
+
+// TriggerParts is a helper function that splits the callback's trigger into the resource and the interaction that trigger it.
+func (c Callback) TriggerParts() (string, string) {
+	index := strings.LastIndex(c.Trigger, "#")
+	if index == -1 {
+		return "", c.Trigger
+	}
+	return c.Trigger[:index], c.Trigger[index+1:]
+}
+
+func validateCallbacks(r Resource) error {
+	seen := map[string]bool{}
+	for _, callback := range r.Callbacks {
+		if callback.ID == "" {
+			return errors.New("Callbacks of " + r.ID + " must have an ID.")
+		}
+		if seen[callback.ID] {
+			return errors.New("Callback " + callback.ID + " is declared more than once.")
+		}
+		seen[callback.ID] = true
+		if resource, _ := callback.TriggerParts(); !strings.Contains(resource, "/") {
+			return errors.New("Callback " + callback.ID + " must be triggered by an interaction in the form {API ID}/{RESOURCE ID}#{INTERACTION ID}, not " + callback.Trigger)
+		}
+		if !strings.Contains(callback.PayloadString, "/") {
+			return errors.New("Callback " + callback.ID + " must send a resource in the form {API ID}/{RESOURCE ID}, not " + callback.PayloadString)
+		}
+		property, ok := r.getProperty(callback.URL)
+		if !ok {
+			return errors.New("Callback " + callback.ID + " is sent to the URL in unknown property: " + callback.URL)
+		}
+		if strings.ToLower(property.Type) != "string" {
+			return errors.New("Callback " + callback.ID + " is sent to the URL in " + callback.URL + ", which is not a string.")
+		}
+		for _, status := range callback.Acknowledge {
+			if status < 200 || status > 299 {
+				return errors.New("Callback " + callback.ID + " is acknowledged with " + strconv.Itoa(status) + ", which is not a success status.")
+			}
+		}
+		if callback.Retry != nil && (callback.Retry.Attempts == "" || callback.Retry.Delay == "") {
+			return errors.New("Callback " + callback.ID + " must declare both the attempts and delay properties of its retries.")
+		}
+	}
+	return nil
+}
+
+// linkCallbacks maps each callback to the resources that trigger it, are sent by it, and hold its retry policy, once all resources are parsed and linked to their parents.
+// The retry properties are held by the resource declaring the callback or one of its ancestors.
+func linkCallbacks(r *Resource, results map[string]*Resource) error {
+	for i, callback := range r.Callbacks {
+		triggerID, interactionID := callback.TriggerParts()
+		trigger, ok := results[triggerID]
+		if !ok {
+			return errors.New("Resource triggering " + r.QualifiedID() + "#" + callback.ID + " not found: " + triggerID)
+		}
+		if _, ok := trigger.GetInteraction(interactionID); !ok {
+			return errors.New("Interaction triggering " + r.QualifiedID() + "#" + callback.ID + " not found: " + callback.Trigger)
+		}
+		payload, ok := results[callback.PayloadString]
+		if !ok {
+			return errors.New("Resource sent by " + r.QualifiedID() + "#" + callback.ID + " not found: " + callback.PayloadString)
+		}
+		r.Callbacks[i].TriggerResource = trigger
+		r.Callbacks[i].Payload = payload
+		if callback.Retry == nil {
+			continue
+		}
+		var holder *Resource
+		for ancestor := r; ancestor != nil; ancestor = ancestor.Parent {
+			if _, ok := ancestor.getProperty(callback.Retry.Attempts); ok {
+				holder = ancestor
+				break
+			}
+		}
+		if holder == nil {
+			return errors.New("Callback " + r.QualifiedID() + "#" + callback.ID + " is retried by unknown property: " + callback.Retry.Attempts)
+		}
+		attempts, _ := holder.getProperty(callback.Retry.Attempts)
+		if strings.ToLower(attempts.Type) != "int" {
+			return errors.New("Callback " + r.QualifiedID() + "#" + callback.ID + " is retried by " + attempts.ID + ", which is not an int.")
+		}
+		delay, ok := holder.getProperty(callback.Retry.Delay)
+		if !ok {
+			return errors.New("Callback " + r.QualifiedID() + "#" + callback.ID + " is delayed by unknown property: " + callback.Retry.Delay)
+		}
+		if strings.ToLower(delay.Type) != "duration" {
+			return errors.New("Callback " + r.QualifiedID() + "#" + callback.ID + " is delayed by " + delay.ID + ", which is not a duration.")
+		}
+		r.Callbacks[i].RetryResource = holder
+	}
+	return nil
+}
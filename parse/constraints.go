@@ -0,0 +1,152 @@
+package parse
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Limit is a helper function that returns a pointer to the passed value, for setting a property's minimum or maximum.
+func Limit(value float64) *float64 {
+	return &value
+}
+
+// hasLength tests whether values of the passed type have a length, rather than a value, that can be constrained.
+func hasLength(t string) bool {
+	switch strings.ToLower(t) {
+	case "string", "bytes", "array":
+		return true
+	}
+	return false
+}
+
+// isNumeric tests whether values of the passed type are numbers that can be constrained.
+func isNumeric(t string) bool {
+	switch strings.ToLower(t) {
+	case "duration", "int", "float":
+		return true
+	}
+	return false
+}
+
+// migrateLengths moves the minimum and maximum of strings, bytes, and arrays to their min_length and max_length.
+// Before min_length and max_length existed, minimum and maximum constrained their lengths.
+func migrateLengths(properties []Property) {
+	for i, property := range properties {
+		if !hasLength(property.Type) || property.MinLength != 0 || property.MaxLength != 0 {
+			continue
+		}
+		if property.Minimum != nil {
+			properties[i].MinLength = int(*property.Minimum)
+			properties[i].Minimum = nil
+		}
+		if property.Maximum != nil {
+			properties[i].MaxLength = int(*property.Maximum)
+			properties[i].Maximum = nil
+		}
+	}
+}
+
+// migrateResource migrates the lengths, nil defaults, and values of every property, param, and header the resource declares.
+func migrateResource(r *Resource) {
+	migrateProperties(r.Properties, true)
+	for i := range r.Shapes {
+		migrateProperties(r.Shapes[i].Properties, true)
+	}
+	if r.OneOf != nil {
+		for i := range r.OneOf.Variants {
+			migrateProperties(r.OneOf.Variants[i].Properties, true)
+		}
+	}
+	for i := range r.Interactions {
+		migrateProperties(r.Interactions[i].Params, false)
+		if r.Interactions[i].Response != nil {
+			migrateProperties(r.Interactions[i].Response.Headers, false)
+		}
+	}
+}
+
+func migrateProperties(properties []Property, nullable bool) {
+	migrateLengths(properties)
+	migrateNilDefaults(properties, nullable)
+	migrateValues(properties)
+}
+
+func validateConstraints(property Property) error {
+	if (property.Minimum != nil || property.Maximum != nil) && !isNumeric(property.Type) && strings.ToLower(property.Type) != "datetime" {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so it cannot have a minimum or maximum.")
+	}
+	if property.MultipleOf != 0 && !isNumeric(property.Type) {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so it cannot have a multiple_of.")
+	}
+	if (property.MinLength != 0 || property.MaxLength != 0) && !hasLength(property.Type) {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so it cannot have a min_length or max_length.")
+	}
+	if property.UniqueItems && strings.ToLower(property.Type) != "array" {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so it cannot have unique_items.")
+	}
+	if (property.KeyPattern != "" || property.MaxKeys != 0) && strings.ToLower(property.Type) != "map" {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so it cannot have a key_pattern or max_keys.")
+	}
+	if strings.ToLower(property.Type) == "map" && property.ValueType == "" {
+		return errors.New("Property " + property.ID + " is a map, so it must declare the value_type of its values.")
+	}
+	if property.MaxKeys < 0 {
+		return errors.New("Property " + property.ID + " cannot have a negative max_keys.")
+	}
+	if _, err := regexp.Compile(property.KeyPattern); err != nil {
+		return errors.New("Property " + property.ID + " has an invalid key_pattern: " + err.Error())
+	}
+	if property.ExclusiveMinimum && property.Minimum == nil {
+		return errors.New("Property " + property.ID + " has an exclusive minimum, but no minimum.")
+	}
+	if property.ExclusiveMaximum && property.Maximum == nil {
+		return errors.New("Property " + property.ID + " has an exclusive maximum, but no maximum.")
+	}
+	if property.Minimum != nil && property.Maximum != nil && *property.Minimum > *property.Maximum {
+		return errors.New("Property " + property.ID + " has a minimum greater than its maximum.")
+	}
+	if property.MinLength < 0 || property.MaxLength < 0 {
+		return errors.New("Property " + property.ID + " cannot have a negative length.")
+	}
+	if property.MaxLength != 0 && property.MinLength > property.MaxLength {
+		return errors.New("Property " + property.ID + " has a min_length greater than its max_length.")
+	}
+	if property.MultipleOf < 0 {
+		return errors.New("Property " + property.ID + " must have a positive multiple_of.")
+	}
+	if property.MultipleOf != 0 && strings.ToLower(property.Type) != "float" && property.MultipleOf != math.Trunc(property.MultipleOf) {
+		return errors.New("Property " + property.ID + " is a " + property.Type + ", so its multiple_of must be a whole number.")
+	}
+	return nil
+}
+
+// validateResourceConstraints checks the constraints, requirement, and values of every property, param, and header the resource declares.
+func validateResourceConstraints(r Resource) error {
+	properties := r.AllProperties()
+	for _, shape := range r.Shapes {
+		properties = append(properties, shape.Properties...)
+	}
+	for _, interaction := range r.Interactions {
+		properties = append(properties, interaction.Params...)
+		if interaction.Response != nil {
+			properties = append(properties, interaction.Response.Headers...)
+		}
+	}
+	for _, property := range properties {
+		err := validateConstraints(property)
+		if err != nil {
+			return err
+		}
+		err = validateRequired(property)
+		if err != nil {
+			return err
+		}
+		err = validateValues(property)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
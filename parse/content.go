@@ -0,0 +1,135 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	ContentJSON      = "json"      // The body is a JSON document
+	ContentBinary    = "binary"    // The body is the raw bytes of a file or other data
+	ContentMultipart = "multipart" // The body is a multipart/form-data document, made of named parts
+	ContentText      = "text"      // The body is plain text
+)
+
+// A Part is the definition of one of the named parts of a multipart request body.
+type Part struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Content     string `yaml:"content,omitempty"`    // What the part holds. Acceptable values: json, binary, text. Defaults to json, which holds the resource
+	MediaType   string `yaml:"media_type,omitempty"` // The Content-Type of the part, if not the default for its content
+	Optional    bool   `yaml:"optional,omitempty"`   // If the part can be left out of the request
+}
+
+// GetContent is a helper function that returns what the interaction's request body holds, defaulting to JSON.
+func (i Interaction) GetContent() string {
+	return getContent(i.Content)
+}
+
+// GetMediaType is a helper function that returns the Content-Type of the interaction's request body.
+func (i Interaction) GetMediaType() string {
+	return getMediaType(i.GetContent(), i.MediaType)
+}
+
+// GetContent is a helper function that returns what the response body holds, defaulting to JSON.
+func (r Response) GetContent() string {
+	return getContent(r.Content)
+}
+
+// GetMediaType is a helper function that returns the Content-Type of the response body.
+func (r Response) GetMediaType() string {
+	return getMediaType(r.GetContent(), r.MediaType)
+}
+
+// GetContent is a helper function that returns what the part holds, defaulting to JSON.
+func (p Part) GetContent() string {
+	return getContent(p.Content)
+}
+
+// GetMediaType is a helper function that returns the Content-Type of the part.
+func (p Part) GetMediaType() string {
+	return getMediaType(p.GetContent(), p.MediaType)
+}
+
+func getContent(content string) string {
+	if content == "" {
+		return ContentJSON
+	}
+	return strings.ToLower(content)
+}
+
+func getMediaType(content, mediaType string) string {
+	if mediaType != "" {
+		return mediaType
+	}
+	switch content {
+	case ContentBinary:
+		return "application/octet-stream"
+	case ContentMultipart:
+		return "multipart/form-data"
+	case ContentText:
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+func validateContent(interaction Interaction) error {
+	switch interaction.GetContent() {
+	case ContentJSON:
+	case ContentBinary, ContentText, ContentMultipart:
+		verb := strings.ToLower(interaction.Verb)
+		if verb != "create" && verb != "update" {
+			return errors.New("Interaction " + interaction.ID + " has no request body, so it cannot declare its content.")
+		}
+		if interaction.AcceptMany {
+			return errors.New("Interaction " + interaction.ID + " accepts many resources, so its request body must be JSON.")
+		}
+	default:
+		return errors.New("Interaction " + interaction.ID + " has unknown content: " + interaction.Content)
+	}
+	if interaction.GetContent() == ContentMultipart && len(interaction.Parts) == 0 {
+		return errors.New("Interaction " + interaction.ID + " has a multipart request body, so it must declare its parts.")
+	}
+	if interaction.GetContent() != ContentMultipart && len(interaction.Parts) > 0 {
+		return errors.New("Interaction " + interaction.ID + " can only declare parts if its request body is multipart.")
+	}
+	seen := map[string]bool{}
+	hasJSON := false
+	for _, part := range interaction.Parts {
+		if part.Name == "" {
+			return errors.New("Parts of " + interaction.ID + " must have a name.")
+		}
+		if seen[part.Name] {
+			return errors.New("Part " + part.Name + " of " + interaction.ID + " is declared more than once.")
+		}
+		seen[part.Name] = true
+		switch part.GetContent() {
+		case ContentJSON:
+			if hasJSON {
+				return errors.New("Interaction " + interaction.ID + " can only have one JSON part, holding the resource.")
+			}
+			hasJSON = true
+		case ContentBinary, ContentText:
+		default:
+			return errors.New("Part " + part.Name + " of " + interaction.ID + " has unknown content: " + part.Content)
+		}
+	}
+	if interaction.Response == nil {
+		return nil
+	}
+	switch interaction.Response.GetContent() {
+	case ContentJSON:
+		return nil
+	case ContentBinary, ContentText:
+	default:
+		return errors.New("Response of " + interaction.ID + " has unknown content: " + interaction.Response.Content)
+	}
+	returns := interaction.Response.Returns
+	if returns == "" && strings.ToLower(interaction.Verb) != "list" && strings.ToLower(interaction.Verb) != "destroy" && !interaction.AcceptMany {
+		returns = ReturnsResource
+	}
+	if returns != ReturnsResource {
+		return errors.New("Response of " + interaction.ID + " can only be " + interaction.Response.Content + " if it returns a single resource.")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldsParam is the ID of the param clients use to select the properties returned by interactions that allow field selection.
+const FieldsParam = "fields"
+
+// ReadableProperties returns the IDs of the resource's properties, including those of its variants, that clients can read, which are the fields that can be selected.
+func (r Resource) ReadableProperties() []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, property := range r.AllProperties() {
+		if property.HasPerm("r") && !seen[property.ID] {
+			ids = append(ids, property.ID)
+			seen[property.ID] = true
+		}
+	}
+	return ids
+}
+
+// expandFields adds the fields param to each of the resource's interactions that allow field selection, unless the interaction declares it itself.
+func expandFields(r *Resource) {
+	var values []interface{}
+	for _, id := range r.ReadableProperties() {
+		values = append(values, id)
+	}
+	for i, interaction := range r.Interactions {
+		if !interaction.Fields {
+			continue
+		}
+		declared := false
+		for _, param := range interaction.Params {
+			if param.ID == FieldsParam {
+				declared = true
+				break
+			}
+		}
+		if declared {
+			continue
+		}
+		r.Interactions[i].Params = append(r.Interactions[i].Params, Property{
+			ID:          FieldsParam,
+			Type:        "string",
+			Description: "A property to include in the response. Omitted properties are left out of the representation. Defaults to all properties.",
+			Values:      values,
+			Required:    boolPtr(false),
+			Repeated:    true,
+		})
+	}
+}
+
+func validateFields(r Resource, interaction Interaction) error {
+	if !interaction.Fields {
+		return nil
+	}
+	verb := strings.ToLower(interaction.Verb)
+	if verb != "get" && verb != "list" {
+		return errors.New("Interaction " + interaction.ID + " allows field selection, but only get and list interactions can.")
+	}
+	if len(r.ReadableProperties()) == 0 {
+		return errors.New("Interaction " + interaction.ID + " allows field selection, but " + r.ID + " has no readable properties.")
+	}
+	return nil
+}
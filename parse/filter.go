@@ -0,0 +1,173 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Filter is the definition of how the results of a list interaction can be narrowed down by the value of one of the resource's properties.
+type Filter struct {
+	Property  string   `yaml:"property"`            // The ID of the property results are filtered by
+	Operators []string `yaml:"operators,omitempty"` // How the property is compared. Acceptable values: eq, in, gt, lt, prefix. Defaults to eq
+}
+
+const (
+	FilterEq     = "eq"     // The property equals the value
+	FilterIn     = "in"     // The property equals one of the values
+	FilterGt     = "gt"     // The property is greater than the value
+	FilterLt     = "lt"     // The property is less than the value
+	FilterPrefix = "prefix" // The property begins with the value
+)
+
+// SortParam is the ID of the param used to choose the order the results of a list interaction are returned in.
+const SortParam = "sort"
+
+// GetOperators is a helper function that returns the operators the filter accepts, defaulting to eq.
+func (f Filter) GetOperators() []string {
+	if len(f.Operators) == 0 {
+		return []string{FilterEq}
+	}
+	return f.Operators
+}
+
+// ParamID returns the ID of the param used to filter by the property with the passed operator, e.g. status or created_at[gt].
+func (f Filter) ParamID(op string) string {
+	if strings.ToLower(op) == FilterEq {
+		return f.Property
+	}
+	return f.Property + "[" + strings.ToLower(op) + "]"
+}
+
+// ParamIDs returns the IDs of all the params used to filter by the property.
+func (f Filter) ParamIDs() []string {
+	var ids []string
+	for _, op := range f.GetOperators() {
+		ids = append(ids, f.ParamID(op))
+	}
+	return ids
+}
+
+// getProperty is a helper function that returns the resource's property with the passed ID.
+func (r Resource) getProperty(id string) (Property, bool) {
+	for _, property := range r.Properties {
+		if property.ID == id {
+			return property, true
+		}
+	}
+	return Property{}, false
+}
+
+// expandFilters adds the params used to filter and sort the results to each of the resource's interactions that declare filters or sortable properties.
+// Params the interaction declares itself override the generated ones.
+func expandFilters(r *Resource) {
+	for i, interaction := range r.Interactions {
+		var generated []Property
+		for _, filter := range interaction.Filters {
+			property, ok := r.getProperty(filter.Property)
+			if !ok {
+				continue // validation will report the unknown property
+			}
+			for _, op := range filter.GetOperators() {
+				generated = append(generated, Property{
+					ID:           filter.ParamID(op),
+					Type:         property.Type,
+					Description:  "Only return results whose " + property.ID + " " + describeOperator(op) + ".",
+					Values:       property.Values,
+					ValueDetails: property.ValueDetails,
+					Required:     boolPtr(false),
+					Repeated:     strings.ToLower(op) == FilterIn,
+				})
+			}
+		}
+		if len(interaction.Sortable) > 0 {
+			var values []interface{}
+			for _, id := range interaction.Sortable {
+				values = append(values, id, "-"+id)
+			}
+			generated = append(generated, Property{
+				ID:          SortParam,
+				Type:        "string",
+				Description: "The property to order the results by. Prefix it with - to reverse the order.",
+				Values:      values,
+				Required:    boolPtr(false),
+			})
+		}
+		declared := map[string]bool{}
+		for _, param := range interaction.Params {
+			declared[param.ID] = true
+		}
+		params := interaction.Params
+		for _, param := range generated {
+			if !declared[param.ID] {
+				params = append(params, param)
+			}
+		}
+		r.Interactions[i].Params = params
+	}
+}
+
+func describeOperator(op string) string {
+	switch strings.ToLower(op) {
+	case FilterIn:
+		return "is one of the values"
+	case FilterGt:
+		return "is greater than the value"
+	case FilterLt:
+		return "is less than the value"
+	case FilterPrefix:
+		return "begins with the value"
+	}
+	return "is the value"
+}
+
+func validateFilters(r Resource, interaction Interaction) error {
+	if (len(interaction.Filters) > 0 || len(interaction.Sortable) > 0) && strings.ToLower(interaction.Verb) != "list" {
+		return errors.New("Interaction " + interaction.ID + " declares filters or sortable properties, but only list interactions can.")
+	}
+	for _, filter := range interaction.Filters {
+		property, ok := r.getProperty(filter.Property)
+		if !ok {
+			return errors.New("Interaction " + interaction.ID + " filters by unknown property: " + filter.Property)
+		}
+		if !property.HasPerm("r") {
+			return errors.New("Interaction " + interaction.ID + " filters by " + filter.Property + ", which clients cannot read.")
+		}
+		for _, op := range filter.GetOperators() {
+			switch strings.ToLower(op) {
+			case FilterEq, FilterIn:
+			case FilterGt, FilterLt:
+				if !isOrdered(property.Type) {
+					return errors.New("Interaction " + interaction.ID + " cannot filter " + filter.Property + " with " + op + ": " + property.Type + " values are not ordered.")
+				}
+			case FilterPrefix:
+				if strings.ToLower(property.Type) != "string" {
+					return errors.New("Interaction " + interaction.ID + " cannot filter " + filter.Property + " with prefix: it is not a string.")
+				}
+			default:
+				return errors.New("Interaction " + interaction.ID + " filters " + filter.Property + " with an unknown operator: " + op)
+			}
+		}
+	}
+	for _, id := range interaction.Sortable {
+		property, ok := r.getProperty(id)
+		if !ok {
+			return errors.New("Interaction " + interaction.ID + " sorts by unknown property: " + id)
+		}
+		if !property.HasPerm("r") {
+			return errors.New("Interaction " + interaction.ID + " sorts by " + id + ", which clients cannot read.")
+		}
+		if !isOrdered(property.Type) {
+			return errors.New("Interaction " + interaction.ID + " cannot sort by " + id + ": " + property.Type + " values are not ordered.")
+		}
+	}
+	return nil
+}
+
+// isOrdered tests whether values of the passed type can be compared with greater than and less than.
+func isOrdered(t string) bool {
+	switch strings.ToLower(t) {
+	case "string", "duration", "datetime", "int", "float":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+// IdempotencyKeyHeader is the header clients use to make retries of idempotent create interactions safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKeyReusedError is the code of the error idempotent interactions return when a key is reused with a different request.
+const IdempotencyKeyReusedError = "idempotency_key_reused"
+
+// expandIdempotency adds the Idempotency-Key header and the idempotency_key_reused error to the resource's idempotent interactions.
+// Params and errors the resource or its API declare themselves are kept.
+func expandIdempotency(r *Resource, api *API) {
+	idempotent := false
+	for i, interaction := range r.Interactions {
+		if !interaction.Idempotent {
+			continue
+		}
+		r.Interactions[i].Params = appendParam(interaction.Params, Property{
+			ID:          IdempotencyKeyHeader,
+			Type:        "string",
+			Description: "A unique key for the request, such as a UUID. Retrying the request with the same key returns the response to the first request instead of performing it again.",
+			Required:    boolPtr(false),
+			In:          ParamInHeader,
+		})
+		if !hasString(interaction.Errors, IdempotencyKeyReusedError) {
+			r.Interactions[i].Errors = append(r.Interactions[i].Errors, IdempotencyKeyReusedError)
+		}
+		idempotent = true
+	}
+	if !idempotent {
+		return
+	}
+	addDefaultError(r, api, Error{
+		Code:        IdempotencyKeyReusedError,
+		Status:      422,
+		Field:       IdempotencyKeyHeader,
+		Description: "The Idempotency-Key was already used for a request with a different body.",
+		Action:      "Use a new Idempotency-Key for each distinct request.",
+	})
+}
+
+func validateIdempotency(interaction Interaction) error {
+	if interaction.Idempotent && strings.ToLower(interaction.Verb) != "create" {
+		return errors.New("Interaction " + interaction.ID + " is idempotent, but only create interactions can declare it.")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Lifecycle is the definition of the states a resource moves through, and the interactions that move it between them.
+type Lifecycle struct {
+	Property    string       `yaml:"property"` // The ID of the property that holds the resource's state
+	States      []State      `yaml:"states"`
+	Transitions []Transition `yaml:"transitions,omitempty"`
+}
+
+// A State is one of the states in a resource's lifecycle.
+type State struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Initial     bool   `yaml:"initial,omitempty"` // If the resource is in this state when it is created
+	Final       bool   `yaml:"final,omitempty"`   // If the resource can't leave this state
+}
+
+// A Transition is the definition of an interaction moving a resource from one state to another.
+type Transition struct {
+	ResourceString string    `yaml:"resource,omitempty"` // The resource the interaction belongs to, if not this one, in the form "{API ID}/{RESOURCE ID}"
+	Resource       *Resource `yaml:"-"`
+	Interaction    string    `yaml:"interaction"` // The ID of the interaction that performs the transition
+	From           []string  `yaml:"from"`        // The states the transition can be performed from
+	To             string    `yaml:"to"`
+	Error          string    `yaml:"error,omitempty"` // The code of the error the interaction returns when the resource is in any other state
+}
+
+// GetState is a helper function that returns the lifecycle's state with the passed ID.
+func (l Lifecycle) GetState(id string) (State, bool) {
+	for _, state := range l.States {
+		if state.ID == id {
+			return state, true
+		}
+	}
+	return State{}, false
+}
+
+// InitialState is a helper function that returns the state resources are created in.
+func (l Lifecycle) InitialState() State {
+	for _, state := range l.States {
+		if state.Initial {
+			return state
+		}
+	}
+	return State{}
+}
+
+// GetInteraction is a helper function that returns the resource's interaction with the passed ID.
+func (r Resource) GetInteraction(id string) (Interaction, bool) {
+	for _, interaction := range r.Interactions {
+		if interaction.ID == id {
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
+
+func validateLifecycle(r Resource) error {
+	if r.Lifecycle == nil {
+		return nil
+	}
+	l := r.Lifecycle
+	property, ok := r.getProperty(l.Property)
+	if !ok {
+		return errors.New("Lifecycle of " + r.ID + " is held in unknown property: " + l.Property)
+	}
+	if strings.ToLower(property.Type) != "string" {
+		return errors.New("Lifecycle of " + r.ID + " must be held in a string property, " + l.Property + " is a " + property.Type)
+	}
+	if property.HasPerm("w") {
+		return errors.New("Lifecycle of " + r.ID + " is held in " + l.Property + ", which clients can write. States can only change through transitions.")
+	}
+	seen := map[string]bool{}
+	initial := 0
+	for _, state := range l.States {
+		if seen[state.ID] {
+			return errors.New("Lifecycle of " + r.ID + " declares state " + state.ID + " more than once.")
+		}
+		seen[state.ID] = true
+		if state.Initial {
+			initial++
+		}
+		if len(property.Values) > 0 && !hasValue(property.Values, state.ID) {
+			return errors.New("State " + state.ID + " is not one of the values of " + l.Property)
+		}
+	}
+	if initial != 1 {
+		return errors.New("Lifecycle of " + r.ID + " must have exactly one initial state.")
+	}
+	for _, transition := range l.Transitions {
+		if transition.ResourceString == "" {
+			if _, ok := r.GetInteraction(transition.Interaction); !ok {
+				return errors.New("Lifecycle of " + r.ID + " is transitioned by unknown interaction: " + transition.Interaction)
+			}
+		}
+		if len(transition.From) == 0 {
+			return errors.New("Transition by " + transition.Interaction + " must declare the states it is performed from.")
+		}
+		for _, from := range transition.From {
+			state, ok := l.GetState(from)
+			if !ok {
+				return errors.New("Transition by " + transition.Interaction + " is performed from unknown state: " + from)
+			}
+			if state.Final {
+				return errors.New("Transition by " + transition.Interaction + " is performed from " + from + ", which is a final state.")
+			}
+		}
+		if _, ok := l.GetState(transition.To); !ok {
+			return errors.New("Transition by " + transition.Interaction + " leads to unknown state: " + transition.To)
+		}
+	}
+	return nil
+}
+
+// linkTransitions maps each transition to the resource whose interaction performs it, once all resources are parsed.
+// The transition's error must be one the interaction returns.
+func linkTransitions(r *Resource, results map[string]*Resource) error {
+	if r.Lifecycle == nil {
+		return nil
+	}
+	for i, transition := range r.Lifecycle.Transitions {
+		performer := r
+		if transition.ResourceString != "" {
+			var ok bool
+			performer, ok = results[transition.ResourceString]
+			if !ok {
+				return errors.New("Resource transitioning " + r.QualifiedID() + " not found: " + transition.ResourceString)
+			}
+		}
+		interaction, ok := performer.GetInteraction(transition.Interaction)
+		if !ok {
+			return errors.New("Interaction transitioning " + r.QualifiedID() + " not found: " + performer.QualifiedID() + "#" + transition.Interaction)
+		}
+		if transition.Error != "" && !hasString(interaction.Errors, transition.Error) {
+			return errors.New("Interaction " + performer.QualifiedID() + "#" + interaction.ID + " must list " + transition.Error + " in its errors, as it returns it when it can't transition " + r.QualifiedID())
+		}
+		r.Lifecycle.Transitions[i].Resource = performer
+	}
+	return nil
+}
+
+func hasValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if s, ok := v.(string); ok && s == value {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Pagination is the definition of how a list interaction returns its results a page at a time.
+type Pagination struct {
+	Style       string `yaml:"style"`                  // How pages are requested. Acceptable values: page, cursor, offset
+	DefaultSize int    `yaml:"default_size,omitempty"` // The number of results returned per page if the client doesn't specify
+	MaxSize     int    `yaml:"max_size,omitempty"`     // The maximum number of results that can be returned per page
+}
+
+const (
+	PaginationPage   = "page"   // Pages are requested by number, with page and per_page params
+	PaginationCursor = "cursor" // Pages are requested with an opaque cursor returned by the previous page, and a limit param
+	PaginationOffset = "offset" // Pages are requested by the number of results to skip, with offset and limit params
+)
+
+// PaginationKey is the key, in the body of a paginated list's response, of the object holding the information needed to request the next page.
+const PaginationKey = "pagination"
+
+// Params returns the params clients use to request a page of results.
+func (p Pagination) Params() []Property {
+	size := Property{
+		ID:          "limit",
+		Type:        "int",
+		Description: "The maximum number of results to return.",
+		Minimum:     Limit(1),
+	}
+	if p.MaxSize != 0 {
+		size.Maximum = Limit(float64(p.MaxSize))
+	}
+	if p.DefaultSize != 0 {
+		size.Default = p.DefaultSize
+	}
+	switch strings.ToLower(p.Style) {
+	case PaginationPage:
+		size.ID = "per_page"
+		size.Description = "The number of results to return per page."
+		return []Property{
+			{ID: "page", Type: "int", Description: "The 0-based page of results to return.", Default: 0},
+			size,
+		}
+	case PaginationCursor:
+		return []Property{
+			{ID: "cursor", Type: "string", Description: "The cursor returned with the previous page of results. Omit it to get the first page.", Required: boolPtr(false)},
+			size,
+		}
+	case PaginationOffset:
+		return []Property{
+			{ID: "offset", Type: "int", Description: "The number of results to skip.", Default: 0},
+			size,
+		}
+	}
+	return []Property{}
+}
+
+// NextKey returns the key, in the response's pagination object, of the value used to request the next page of results.
+func (p Pagination) NextKey() string {
+	switch strings.ToLower(p.Style) {
+	case PaginationPage:
+		return "next_page"
+	case PaginationCursor:
+		return "next_cursor"
+	case PaginationOffset:
+		return "next_offset"
+	}
+	return ""
+}
+
+func validatePagination(interaction Interaction) error {
+	p := interaction.Pagination
+	if strings.ToLower(interaction.Verb) != "list" {
+		return errors.New("Interaction " + interaction.ID + " is paginated, but only list interactions can be paginated.")
+	}
+	switch strings.ToLower(p.Style) {
+	case PaginationPage, PaginationCursor, PaginationOffset:
+	default:
+		return errors.New("Interaction " + interaction.ID + " has an unknown pagination style: " + p.Style)
+	}
+	if p.MaxSize != 0 && p.DefaultSize > p.MaxSize {
+		return errors.New("Interaction " + interaction.ID + " has a default page size larger than its maximum page size.")
+	}
+	return nil
+}
+
+// expandPagination adds the params clients use to request pages to each paginated interaction of the resource.
+// Params the interaction declares itself override the generated ones.
+func expandPagination(r *Resource) {
+	for i, interaction := range r.Interactions {
+		if interaction.Pagination == nil {
+			continue
+		}
+		declared := map[string]bool{}
+		for _, param := range interaction.Params {
+			declared[param.ID] = true
+		}
+		var params []Property
+		for _, param := range interaction.Pagination.Params() {
+			if !declared[param.ID] {
+				params = append(params, param)
+			}
+		}
+		r.Interactions[i].Params = append(params, interaction.Params...)
+	}
+}
@@ -0,0 +1,110 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	OnParentDeleteCascade  = "cascade"  // The resource is destroyed along with its parent
+	OnParentDeleteRestrict = "restrict" // The parent cannot be destroyed while the resource exists
+	OnParentDeleteOrphan   = "orphan"   // The resource outlives its parent
+)
+
+// GetOnParentDelete is a helper function that returns what happens to the resource when its parent is destroyed, defaulting to cascade.
+func (r Resource) GetOnParentDelete() string {
+	if r.OnParentDelete == "" {
+		return OnParentDeleteCascade
+	}
+	return strings.ToLower(r.OnParentDelete)
+}
+
+// RestrictedError returns the code of the error the resource's parent returns when it can't be destroyed because the resource exists under it.
+func (r Resource) RestrictedError() string {
+	return "has_" + r.URLPrefix
+}
+
+// linkRestrictions adds the error returned when a resource that restricts its parent's deletion still exists to the parent's destroy interactions,
+// once all resources are linked to their parents. Errors the parent or its API declare themselves are kept.
+func linkRestrictions(r *Resource) {
+	if r.Parent == nil || r.ParentIsCollection || strings.ToLower(r.OnParentDelete) != OnParentDeleteRestrict {
+		return
+	}
+	parent := r.Parent
+	code := r.RestrictedError()
+	if _, ok := parent.GetError(code); !ok {
+		parent.Errors = append(parent.Errors, Error{
+			Code:        code,
+			Status:      409,
+			Description: "The " + parent.Name + " cannot be destroyed while a " + r.Name + " exists under it.",
+			Action:      "Destroy every " + r.Name + " under the " + parent.Name + ", then destroy the " + parent.Name + " again.",
+		})
+	}
+	for i, interaction := range parent.Interactions {
+		if strings.ToLower(interaction.Verb) == "destroy" && !hasString(interaction.Errors, code) {
+			parent.Interactions[i].Errors = append(parent.Interactions[i].Errors, code)
+		}
+	}
+}
+
+func validateReferences(r Resource) error {
+	switch strings.ToLower(r.OnParentDelete) {
+	case "":
+	case OnParentDeleteCascade, OnParentDeleteRestrict, OnParentDeleteOrphan:
+		if r.ParentString == "" {
+			return errors.New("Resource " + r.ID + " sets on_parent_delete, but has no parent.")
+		}
+		if r.ParentIsCollection {
+			return errors.New("Resource " + r.ID + " sets on_parent_delete, but its parent is a collection, which can't be destroyed.")
+		}
+	default:
+		return errors.New("Unknown on_parent_delete value: " + r.OnParentDelete)
+	}
+	for _, property := range r.AllProperties() {
+		if property.References == "" {
+			continue
+		}
+		if !strings.Contains(property.References, "/") {
+			return errors.New("Property " + property.ID + " must reference a resource in the form {API ID}/{RESOURCE ID}, not " + property.References)
+		}
+		switch strings.ToLower(property.Type) {
+		case "string":
+			if !strings.HasSuffix(property.ID, "_id") {
+				return errors.New("Property " + property.ID + " references " + property.References + ", so its ID must end in _id.")
+			}
+		case "array":
+			if strings.ToLower(property.ValueType) != "string" {
+				return errors.New("Property " + property.ID + " references " + property.References + ", so it must be an array of strings.")
+			}
+			if !strings.HasSuffix(property.ID, "_ids") {
+				return errors.New("Property " + property.ID + " references " + property.References + ", so its ID must end in _ids.")
+			}
+		default:
+			return errors.New("Property " + property.ID + " references " + property.References + ", so it must be a string or an array of strings.")
+		}
+	}
+	return nil
+}
+
+// linkReferences points the properties of the resource, identified by key, and its variants that reference other resources at them.
+func linkReferences(key string, r *Resource, results map[string]*Resource) error {
+	lists := [][]Property{r.Properties}
+	if r.OneOf != nil {
+		for _, variant := range r.OneOf.Variants {
+			lists = append(lists, variant.Properties)
+		}
+	}
+	for _, properties := range lists {
+		for i, property := range properties {
+			if property.References == "" {
+				continue
+			}
+			referenced, ok := results[property.References]
+			if !ok {
+				return errors.New("Resource referenced by " + key + "." + property.ID + " not found: " + property.References)
+			}
+			properties[i].Referenced = referenced // properties shares its backing array with the resource or variant, so this links the original
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package parse
+
+import (
+	"errors"
+)
+
+// NilDefault is the default that signifies a property is not set by default. Properties using it are migrated to be optional and nullable, with no default.
+const NilDefault = "nil"
+
+// IsRequired is a helper function that tests whether the property must be set.
+// Unless the property says otherwise, it is required when it has no default.
+func (p Property) IsRequired() bool {
+	if p.Required != nil {
+		return *p.Required
+	}
+	return p.Default == nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// migrateNilDefaults replaces the nil default with an optional property that has no default.
+// When it's used on a property of a resource, it also means the property is null when it's not set.
+func migrateNilDefaults(properties []Property, nullable bool) {
+	for i, property := range properties {
+		if s, ok := property.Default.(string); !ok || s != NilDefault {
+			continue
+		}
+		properties[i].Default = nil
+		if properties[i].Required == nil {
+			properties[i].Required = boolPtr(false)
+		}
+		if nullable {
+			properties[i].Nullable = true
+		}
+	}
+}
+
+func validateRequired(property Property) error {
+	if property.Required != nil && *property.Required && property.Default != nil {
+		return errors.New("Property " + property.ID + " is required, so it cannot have a default.")
+	}
+	return nil
+}
@@ -14,36 +14,136 @@ type Resource struct {
 	ID                 string        `yaml:"id"`
 	Name               string        `yaml:"name"`
 	Description        string        `yaml:"description"`
+	API                *API          `yaml:"-"`
 	Parent             *Resource     `yaml:"-"`
 	ParentString       string        `yaml:"parent,omitempty"`
 	ParentIsCollection bool          `yaml:"parent_is_collection,omitempty"`
-	URLSlug            string        `yaml:"url_slug"`
+	URLSlug            string        `yaml:"url_slug,omitempty"`
+	Singleton          bool          `yaml:"singleton,omitempty"` // If the resource exists once per parent, so it has no slug
 	URLPrefix          string        `yaml:"url_prefix"`
 	Properties         []Property    `yaml:"properties"`
 	Interactions       []Interaction `yaml:"interactions,omitempty"`
+	Errors             []Error       `yaml:"errors,omitempty"`           // The errors interactions against this resource can return
+	Shapes             []Shape       `yaml:"shapes,omitempty"`           // Ad-hoc representations interactions can return instead of the resource
+	Traits             []string      `yaml:"traits,omitempty"`           // The IDs of the API's traits the resource includes
+	OnParentDelete     string        `yaml:"on_parent_delete,omitempty"` // What happens to the resource when its parent is destroyed. Acceptable values: cascade, restrict, orphan
+	Lifecycle          *Lifecycle    `yaml:"lifecycle,omitempty"`        // The states the resource moves through
+	Callbacks          []Callback    `yaml:"callbacks,omitempty"`        // HTTP requests the API sends to URLs held by the resource
+	Versioned          bool          `yaml:"versioned,omitempty"`        // If the resource is returned with an ETag, and accepts conditional requests
+	OneOf              *OneOf        `yaml:"one_of,omitempty"`           // The variants of the resource, and the properties each accepts
+}
+
+const (
+	ErrorFormatJarvis  = "jarvis"  // Errors are returned as a list of error objects under an "errors" key
+	ErrorFormatProblem = "problem" // Errors are returned as RFC 7807 application/problem+json documents
+)
+
+// GetErrorFormat is a helper function that returns the representation the resource's errors are returned in, which is set by its API.
+func (r Resource) GetErrorFormat() string {
+	if r.API == nil || r.API.ErrorFormat == "" {
+		return ErrorFormatJarvis
+	}
+	return r.API.ErrorFormat
 }
 
 // A Property is a definition of a specific field or property in a resource being returned by an API. It contains the information and constraints about the field.
 type Property struct {
-	ID          string        `yaml:"id"`
-	Type        string        `yaml:"type"`
-	Description string        `yaml:"description"`
-	Values      []interface{} `yaml:"values,omitempty"`  // A list of acceptable values
-	Default     interface{}   `yaml:"default,omitempty"` // The default value, if this property is optional
-	Maximum     int           `yaml:"maximum,omitempty"`
-	Minimum     int           `yaml:"minimum,omitempty"`
-	Permissions []string      `yaml:"permissions,omitempty"` // Permissions clients have for this property. Acceptable values: r, w
-	Repeated    bool          `yaml:"repeated,omitempty"`    // If this property can appear more than once in URL parameters
+	ID               string        `yaml:"id"`
+	Type             string        `yaml:"type"`
+	Description      string        `yaml:"description"`
+	Values           []interface{} `yaml:"values,omitempty"`            // A list of acceptable values
+	ValueDetails     []Value       `yaml:"-"`                           // The documentation of each of the acceptable values
+	Default          interface{}   `yaml:"default,omitempty"`           // The default value, if this property is optional
+	Required         *bool         `yaml:"required,omitempty"`          // If the property must be set. Defaults to true, unless the property has a default
+	Nullable         bool          `yaml:"nullable,omitempty"`          // If the property's value can be null
+	Maximum          *float64      `yaml:"maximum,omitempty"`           // The largest value a number can have
+	Minimum          *float64      `yaml:"minimum,omitempty"`           // The smallest value a number can have
+	ExclusiveMaximum bool          `yaml:"exclusive_maximum,omitempty"` // If the value must be less than the maximum, not equal to it
+	ExclusiveMinimum bool          `yaml:"exclusive_minimum,omitempty"` // If the value must be greater than the minimum, not equal to it
+	MultipleOf       float64       `yaml:"multiple_of,omitempty"`       // A number the value must be a multiple of
+	MaxLength        int           `yaml:"max_length,omitempty"`        // The longest a string, bytes, or array can be
+	MinLength        int           `yaml:"min_length,omitempty"`        // The shortest a string, bytes, or array can be
+	UniqueItems      bool          `yaml:"unique_items,omitempty"`      // If the values of an array must all be different
+	Permissions      []string      `yaml:"permissions,omitempty"`       // Permissions clients have for this property. Acceptable values: r, w
+	Repeated         bool          `yaml:"repeated,omitempty"`          // If this property can appear more than once in URL parameters
+	ValueType        string        `yaml:"value_type,omitempty"`        // The type of the values of an array or map, or the type a pointer points to
+	KeyPattern       string        `yaml:"key_pattern,omitempty"`       // A regular expression the keys of a map must match
+	MaxKeys          int           `yaml:"max_keys,omitempty"`          // The most keys a map can hold
+	In               string        `yaml:"in,omitempty"`                // Where a param is passed. Acceptable values: query, header, path, cookie
+	References       string        `yaml:"references,omitempty"`        // The resource whose ID this property holds, in the form "{API ID}/{RESOURCE ID}"
+	Referenced       *Resource     `yaml:"-"`
+	Timer            string        `yaml:"timer,omitempty"` // What happens when a duration elapses. Acceptable values: hide, lock, expire, retry
+	When             *Condition    `yaml:"when,omitempty"`  // The values of another property the property is only allowed with
 }
 
+const (
+	ParamInQuery  = "query"
+	ParamInHeader = "header"
+	ParamInPath   = "path"
+	ParamInCookie = "cookie"
+)
+
 // An Interaction is the definition of a specific action that can be performed against a resource using the API. It contains the information and constraints of that action.
 type Interaction struct {
+	ID          string      `yaml:"id"`
+	Name        string      `yaml:"name"`
+	Verb        string      `yaml:"verb"`
+	Description string      `yaml:"description"`
+	Params      []Property  `yaml:"params,omitempty"`      // Properties passed as query, header, path, or cookie params
+	AcceptMany  bool        `yaml:"accept_many,omitempty"` // expect an array, not a single resource
+	Errors      []string    `yaml:"errors,omitempty"`      // Codes of the resource's errors this interaction can return
+	Response    *Response   `yaml:"response,omitempty"`    // What the interaction returns. If not set, it is inferred from the verb
+	Scopes      []string    `yaml:"scopes,omitempty"`      // The OAuth2 scopes a client must be granted to perform the interaction
+	Traits      []string    `yaml:"traits,omitempty"`      // The IDs of the API's traits whose params the interaction includes
+	Pagination  *Pagination `yaml:"pagination,omitempty"`  // How a list interaction returns its results a page at a time
+	Filters     []Filter    `yaml:"filters,omitempty"`     // The properties the results of a list interaction can be filtered by
+	Sortable    []string    `yaml:"sortable,omitempty"`    // The IDs of the properties the results of a list interaction can be sorted by
+	Fields      bool        `yaml:"fields,omitempty"`      // If clients can select the properties returned by a get or list interaction using the fields param
+	Atomicity   string      `yaml:"atomicity,omitempty"`   // How an accept_many interaction handles some of the resources failing. Acceptable values: atomic, per_item
+	Idempotent  bool        `yaml:"idempotent,omitempty"`  // If a create interaction can be safely retried by sending an Idempotency-Key header
+	Scope       string      `yaml:"scope,omitempty"`       // What the interaction acts on. Acceptable values: collection, instance
+	Content     string      `yaml:"content,omitempty"`     // What the request body holds. Acceptable values: json, binary, multipart, text
+	MediaType   string      `yaml:"media_type,omitempty"`  // The Content-Type of the request body, if not the default for its content
+	Parts       []Part      `yaml:"parts,omitempty"`       // The named parts of a multipart request body
+}
+
+// A Response is the definition of what an interaction returns when it succeeds.
+type Response struct {
+	Status            int         `yaml:"status,omitempty"`  // The HTTP status code returned
+	Returns           string      `yaml:"returns,omitempty"` // What the body contains. Acceptable values: resource, list, nothing, shape, results
+	Resource          *Resource   `yaml:"-"`
+	ResourceString    string      `yaml:"resource,omitempty"` // The resource returned, if not the interaction's, in the form "{API ID}/{RESOURCE ID}"
+	Shape             string      `yaml:"shape,omitempty"`    // The ID of the resource's shape returned, when Returns is shape
+	Includes          []string    `yaml:"includes,omitempty"` // Other resources returned alongside, in the form "{API ID}/{RESOURCE ID}"
+	IncludedResources []*Resource `yaml:"-"`
+	Key               string      `yaml:"key,omitempty"`        // The key the body is enveloped in
+	Headers           []Property  `yaml:"headers,omitempty"`    // Headers returned with the response, such as Location
+	Content           string      `yaml:"content,omitempty"`    // What the body holds. Acceptable values: json, binary, text
+	MediaType         string      `yaml:"media_type,omitempty"` // The Content-Type of the body, if not the default for its content
+}
+
+const (
+	ReturnsResource = "resource"
+	ReturnsList     = "list"
+	ReturnsNothing  = "nothing"
+	ReturnsShape    = "shape"
+	ReturnsResults  = "results" // A result for each of the resources a per_item interaction accepted
+)
+
+// A Shape is a named, ad-hoc representation that can be returned by an interaction instead of the resource itself.
+type Shape struct {
 	ID          string     `yaml:"id"`
-	Name        string     `yaml:"name"`
-	Verb        string     `yaml:"verb"`
 	Description string     `yaml:"description"`
-	Params      []Property `yaml:"params,omitempty"`      // Properties passed as URL params
-	AcceptMany  bool       `yaml:"accept_many,omitempty"` // expect an array, not a single resource
+	Properties  []Property `yaml:"properties"`
+}
+
+// An Error is the definition of an error that can be returned by an interaction. Each error must be resolvable by a single action.
+type Error struct {
+	Code        string `yaml:"code"`            // A machine-readable identifier for the error
+	Status      int    `yaml:"status"`          // The HTTP status code the error is returned with
+	Field       string `yaml:"field,omitempty"` // The ID of the property or param that caused the error
+	Description string `yaml:"description"`
+	Action      string `yaml:"action"` // The one action necessary to resolve the error
 }
 
 // ParseFile will read the specified resource file and parse it into a Resource, which is then returned.
@@ -72,17 +172,16 @@ func createImportList(root, path string) (map[string]string, error) {
 		if !strings.HasSuffix(p, ".yml") {
 			return nil // skip non-yaml files
 		}
+		if info.Name() == APIFile {
+			return nil // skip the API's description, it's not a resource
+		}
 		results[strings.TrimPrefix(p, root)] = p
 		return nil
 	})
 	return results, err
 }
 
-func importParents(root string, r Resource, cache map[string]bool) (map[string]*Resource, error) {
-	if r.ParentString == "" {
-		return map[string]*Resource{}, nil
-	}
-	path := getParentPath(r)
+func importPath(root, path string, cache map[string]bool) (map[string]*Resource, error) {
 	if path == "" {
 		return map[string]*Resource{}, nil
 	}
@@ -97,6 +196,40 @@ func importParents(root string, r Resource, cache map[string]bool) (map[string]*
 	return results, nil
 }
 
+// getReferences returns the IDs of all the other resources the resource refers to.
+func getReferences(r Resource) []string {
+	var refs []string
+	if r.ParentString != "" {
+		refs = append(refs, r.ParentString)
+	}
+	for _, interaction := range r.Interactions {
+		if interaction.Response == nil {
+			continue
+		}
+		if interaction.Response.ResourceString != "" {
+			refs = append(refs, interaction.Response.ResourceString)
+		}
+		refs = append(refs, interaction.Response.Includes...)
+	}
+	for _, property := range r.AllProperties() {
+		if property.References != "" {
+			refs = append(refs, property.References)
+		}
+	}
+	if r.Lifecycle != nil {
+		for _, transition := range r.Lifecycle.Transitions {
+			if transition.ResourceString != "" {
+				refs = append(refs, transition.ResourceString)
+			}
+		}
+	}
+	for _, callback := range r.Callbacks {
+		trigger, _ := callback.TriggerParts()
+		refs = append(refs, trigger, callback.PayloadString)
+	}
+	return refs
+}
+
 // Parse will find all resource files in the specified directory and parse them into Resources, which are then returned.
 func Parse(root, path string) (map[string]*Resource, error) {
 	results := map[string]*Resource{}
@@ -107,25 +240,58 @@ func Parse(root, path string) (map[string]*Resource, error) {
 		return results, err
 	}
 
+	api, err := parseAPI(root, path)
+	if err != nil {
+		return results, err
+	}
+
 	for id, filePath := range toImport {
 		r, err := ParseFile(filePath)
 		if err != nil {
 			return results, errors.New("Error parsing " + id + ": " + err.Error())
 		}
+		err = expandTraits(&r, api)
+		if err != nil {
+			return results, errors.New("Error parsing " + id + ": " + err.Error())
+		}
+		migrateResource(&r)
+		expandPagination(&r)
+		expandFilters(&r)
+		expandFields(&r)
+		expandVersioning(&r, api)
+		expandIdempotency(&r, api)
+		r.API = api
+		err = validateResource(r)
+		if err != nil {
+			return results, errors.New("Error parsing " + id + ": " + err.Error())
+		}
+		api.Resources = append(api.Resources, &r)
 		myPath := getResourcePath(id)
-		if getParentPath(r) != myPath {
-			parents, err := importParents(root, r, importCache)
+		for _, ref := range getReferences(r) {
+			refPath := getResourcePath(ref)
+			if refPath == myPath {
+				continue
+			}
+			imported, err := importPath(root, refPath, importCache)
 			if err != nil {
 				return results, err
 			}
-			for parentPath, parent := range parents {
-				results[parentPath] = parent
+			for importedPath, resource := range imported {
+				results[importedPath] = resource
 			}
 		}
 
 		results[path+"/"+r.ID] = &r
 	}
 
+	if api.ErrorFormat == "" {
+		api.ErrorFormat = ErrorFormatJarvis
+	}
+	err = validateAPI(api)
+	if err != nil {
+		return results, errors.New("Error parsing " + path + ": " + err.Error())
+	}
+
 	// map our resources to their parents
 	for k, r := range results {
 		if r.ParentString == "" {
@@ -137,14 +303,58 @@ func Parse(root, path string) (map[string]*Resource, error) {
 			return results, errors.New("Error parsing " + path + ": Parent of " + k + " not found: " + r.ParentString)
 		}
 	}
-	return results, err
-}
+	for _, r := range results {
+		linkRestrictions(r)
+	}
 
-func getParentPath(resource Resource) string {
-	if resource.ParentString == "" {
-		return ""
+	// map our properties to the resources they reference
+	for k, r := range results {
+		err = linkReferences(k, r, results)
+		if err != nil {
+			return results, errors.New("Error parsing " + path + ": " + err.Error())
+		}
+	}
+
+	// map our lifecycle transitions to the resources that perform them
+	for _, r := range results {
+		err = linkTransitions(r, results)
+		if err != nil {
+			return results, errors.New("Error parsing " + path + ": " + err.Error())
+		}
+	}
+
+	// map our callbacks to the resources they involve
+	for _, r := range results {
+		err = linkCallbacks(r, results)
+		if err != nil {
+			return results, errors.New("Error parsing " + path + ": " + err.Error())
+		}
+	}
+
+	// map our responses to the resources they return
+	for k, r := range results {
+		for _, interaction := range r.Interactions {
+			if interaction.Response == nil {
+				continue
+			}
+			if interaction.Response.ResourceString != "" {
+				returned, ok := results[interaction.Response.ResourceString]
+				if !ok {
+					return results, errors.New("Error parsing " + path + ": Resource returned by " + k + "#" + interaction.ID + " not found: " + interaction.Response.ResourceString)
+				}
+				interaction.Response.Resource = returned
+			}
+			interaction.Response.IncludedResources = nil
+			for _, include := range interaction.Response.Includes {
+				included, ok := results[include]
+				if !ok {
+					return results, errors.New("Error parsing " + path + ": Resource included by " + k + "#" + interaction.ID + " not found: " + include)
+				}
+				interaction.Response.IncludedResources = append(interaction.Response.IncludedResources, included)
+			}
+		}
 	}
-	return getResourcePath(resource.ParentString)
+	return results, err
 }
 
 func getResourcePath(path string) string {
@@ -164,3 +374,55 @@ func (p Property) HasPerm(perm string) bool {
 	}
 	return false
 }
+
+// QualifiedID is a helper function that returns the resource's ID in the form "{API ID}/{RESOURCE ID}".
+func (r Resource) QualifiedID() string {
+	if r.API == nil {
+		return r.ID
+	}
+	return r.API.ID + "/" + r.ID
+}
+
+// GetError is a helper function that returns the error with the passed in code from the resource's error catalog, or its API's.
+func (r Resource) GetError(code string) (Error, bool) {
+	for _, e := range r.Errors {
+		if e.Code == code {
+			return e, true
+		}
+	}
+	if r.API != nil {
+		return r.API.GetError(code)
+	}
+	return Error{}, false
+}
+
+// addDefaultError adds the error to the resource's error catalog, unless the resource or its API already declare an error with its code.
+func addDefaultError(r *Resource, api *API, e Error) {
+	for _, existing := range r.Errors {
+		if existing.Code == e.Code {
+			return
+		}
+	}
+	if _, ok := api.GetError(e.Code); ok {
+		return
+	}
+	r.Errors = append(r.Errors, e)
+}
+
+// GetShape is a helper function that returns the resource's shape with the passed in ID.
+func (r Resource) GetShape(id string) (Shape, bool) {
+	for _, shape := range r.Shapes {
+		if shape.ID == id {
+			return shape, true
+		}
+	}
+	return Shape{}, false
+}
+
+// Location is a helper function that returns where the param is passed, defaulting to the query string.
+func (p Property) Location() string {
+	if p.In == "" {
+		return ParamInQuery
+	}
+	return strings.ToLower(p.In)
+}
@@ -0,0 +1,629 @@
+package parse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// the sample resources should always parse cleanly
+func TestParseSampleResources(t *testing.T) {
+	for _, dir := range []string{"common", "mq"} {
+		_, err := Parse("../sample-resources/", dir)
+		if err != nil {
+			t.Errorf("Error parsing sample resources in %s: %s", dir, err)
+		}
+	}
+}
+
+var invalidResources = map[string]Resource{
+	"unknown error code": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "get", Verb: "get", Errors: []string{"queue_not_found"}}},
+	},
+	"duplicate error code": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Errors: []Error{
+			{Code: "queue_not_found", Status: 404, Action: "Create the queue."},
+			{Code: "queue_not_found", Status: 404, Action: "Create the queue."},
+		},
+	},
+	"non-error status": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Errors:  []Error{{Code: "queue_not_found", Status: 200, Action: "Create the queue."}},
+	},
+	"client error without action": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Errors:  []Error{{Code: "queue_not_found", Status: 404}},
+	},
+	"unknown error field": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Errors:  []Error{{Code: "name_taken", Status: 409, Field: "name", Action: "Choose another name."}},
+	},
+	"unknown param location": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "list", Verb: "list", Params: []Property{{ID: "page", Type: "int", In: "body"}}}},
+	},
+	"path param with default": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "list", Verb: "list", Params: []Property{{ID: "version", Type: "string", In: "path", Default: "v1"}}}},
+	},
+	"scopes without security": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "get", Verb: "get", Scopes: []string{"queues:read"}}},
+	},
+	"filter on non-list": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Properties:   []Property{{ID: "name", Type: "string", Permissions: []string{"r"}}},
+		Interactions: []Interaction{{ID: "get", Verb: "get", Filters: []Filter{{Property: "name"}}}},
+	},
+	"filter on unknown property": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "list", Verb: "list", Filters: []Filter{{Property: "name"}}}},
+	},
+	"prefix filter on non-string": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Properties:   []Property{{ID: "size", Type: "int", Permissions: []string{"r"}}},
+		Interactions: []Interaction{{ID: "list", Verb: "list", Filters: []Filter{{Property: "size", Operators: []string{"prefix"}}}}},
+	},
+	"sort by unordered property": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Properties:   []Property{{ID: "enabled", Type: "boolean", Permissions: []string{"r"}}},
+		Interactions: []Interaction{{ID: "list", Verb: "list", Sortable: []string{"enabled"}}},
+	},
+	"field selection on create": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Properties:   []Property{{ID: "name", Type: "string", Permissions: []string{"r", "w"}}},
+		Interactions: []Interaction{{ID: "create", Verb: "create", Fields: true}},
+	},
+	"reference without _id suffix": Resource{
+		ID:         "reservation",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "message", Type: "string", References: "mq/message"}},
+	},
+	"on_parent_delete without parent": Resource{
+		ID:             "queue",
+		URLSlug:        "id",
+		OnParentDelete: "cascade",
+	},
+	"writable lifecycle property": Resource{
+		ID:         "message",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "state", Type: "string", Permissions: []string{"r", "w"}}},
+		Lifecycle:  &Lifecycle{Property: "state", States: []State{{ID: "available", Initial: true}}},
+	},
+	"lifecycle without initial state": Resource{
+		ID:         "message",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "state", Type: "string", Permissions: []string{"r"}}},
+		Lifecycle:  &Lifecycle{Property: "state", States: []State{{ID: "available"}}},
+	},
+	"transition from final state": Resource{
+		ID:           "message",
+		URLSlug:      "id",
+		Properties:   []Property{{ID: "state", Type: "string", Permissions: []string{"r"}}},
+		Interactions: []Interaction{{ID: "delete", Verb: "destroy"}},
+		Lifecycle: &Lifecycle{
+			Property:    "state",
+			States:      []State{{ID: "available", Initial: true}, {ID: "deleted", Final: true}},
+			Transitions: []Transition{{Interaction: "delete", From: []string{"deleted"}, To: "deleted"}},
+		},
+	},
+	"timer on non-duration": Resource{
+		ID:         "message",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "expires_at", Type: "datetime", Timer: "expire"}},
+	},
+	"callback to unknown URL property": Resource{
+		ID:        "subscriber",
+		URLSlug:   "id",
+		Callbacks: []Callback{{ID: "push", Trigger: "mq/message#push", URL: "url", PayloadString: "mq/message"}},
+	},
+	"callback acknowledged with error status": Resource{
+		ID:         "subscriber",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "url", Type: "string"}},
+		Callbacks:  []Callback{{ID: "push", Trigger: "mq/message#push", URL: "url", PayloadString: "mq/message", Acknowledge: []int{500}}},
+	},
+	"atomicity without accept_many": Resource{
+		ID:           "message",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "push", Verb: "create", Atomicity: "per_item"}},
+	},
+	"slug-less resource": Resource{
+		ID: "queue",
+	},
+	"singleton with a slug": Resource{
+		ID:        "webhook",
+		URLSlug:   "id",
+		Singleton: true,
+	},
+	"singleton creating itself": Resource{
+		ID:           "webhook",
+		Singleton:    true,
+		API:          &API{ID: "mq"},
+		Interactions: []Interaction{{ID: "create", Verb: "create", Response: &Response{ResourceString: "mq/webhook"}}},
+	},
+	"singleton list": Resource{
+		ID:           "webhook",
+		Singleton:    true,
+		Interactions: []Interaction{{ID: "list", Verb: "list"}},
+	},
+	"instance-scoped list": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "list", Verb: "list", Scope: "instance"}},
+	},
+	"minimum on a boolean": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "enabled", Type: "boolean", Minimum: Limit(1)}},
+	},
+	"exclusive maximum without maximum": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "ratio", Type: "float", ExclusiveMaximum: true}},
+	},
+	"fractional multiple_of on an int": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "size", Type: "int", MultipleOf: 0.5}},
+	},
+	"idempotent update": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "update", Verb: "update", Idempotent: true}},
+	},
+	"required property with a default": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "size", Type: "int", Default: 10, Required: boolPtr(true)}},
+	},
+	"default to a deprecated value": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Default: "pull", Values: []interface{}{"pull", "push"},
+			ValueDetails: []Value{{Value: "pull", Deprecated: true}, {Value: "push"}}}},
+	},
+	"unknown discriminator": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "type", Variants: []Variant{{Value: "pull"}}},
+	},
+	"variant value not a value of the discriminator": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: "unicast"}}},
+	},
+	"variant value that is a list": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: []interface{}{"push"}}}},
+	},
+	"value that is a list": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", []interface{}{"push"}}}},
+	},
+	"condition on a list value": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}},
+			{ID: "retries", Type: "int", When: &Condition{Property: "push_type", In: []interface{}{[]interface{}{"push"}}}}},
+	},
+	"variant property with an unknown timer": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: "push", Properties: []Property{{ID: "delay", Type: "duration", Timer: "bogus"}}}}},
+	},
+	"variant property with an invalid reference": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: "push", Properties: []Property{{ID: "webhook_id", Type: "string", References: "nope"}}}}},
+	},
+	"variant property already on the resource": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}, {ID: "retries", Type: "int"}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: "push", Properties: []Property{{ID: "retries", Type: "int"}}}}},
+	},
+	"condition on a property without values": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "name", Type: "string"}, {ID: "retries", Type: "int", When: &Condition{Property: "name", In: []interface{}{"a"}}}},
+	},
+	"condition on an unknown value": Resource{
+		ID:      "queue",
+		URLSlug: "id",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}},
+			{ID: "retries", Type: "int", When: &Condition{Property: "push_type", NotIn: []interface{}{"unicast"}}}},
+	},
+	"map without a value_type": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "metadata", Type: "map"}},
+	},
+	"key_pattern on a string": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "name", Type: "string", KeyPattern: "^[a-z]+$"}},
+	},
+	"invalid key_pattern": Resource{
+		ID:         "queue",
+		URLSlug:    "id",
+		Properties: []Property{{ID: "metadata", Type: "map", ValueType: "string", KeyPattern: "[a-z"}},
+	},
+	"binary get": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "get", Verb: "get", Content: ContentBinary}},
+	},
+	"multipart without parts": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "create", Verb: "create", Content: ContentMultipart}},
+	},
+	"two JSON parts": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "create", Verb: "create", Content: ContentMultipart, Parts: []Part{{Name: "a"}, {Name: "b"}}}},
+	},
+	"binary list response": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "list", Verb: "list", Response: &Response{Content: ContentBinary}}},
+	},
+	"on_parent_delete under a collection": Resource{
+		ID:                 "webhook",
+		URLSlug:            "id",
+		ParentString:       "mq/message",
+		ParentIsCollection: true,
+		OnParentDelete:     OnParentDeleteCascade,
+	},
+	"optional path param": Resource{
+		ID:           "queue",
+		URLSlug:      "id",
+		Interactions: []Interaction{{ID: "get", Verb: "get", Params: []Property{{ID: "region", Type: "string", In: ParamInPath, Required: boolPtr(false)}}}},
+	},
+}
+
+func TestInvalidResources(t *testing.T) {
+	for name, resource := range invalidResources {
+		err := validateResource(resource)
+		if err == nil {
+			resource.API = &API{ID: "test", Resources: []*Resource{&resource}}
+			err = validateAPI(resource.API)
+		}
+		if err == nil {
+			t.Errorf("Expected an error validating resource with %s, got nil.", name)
+		}
+	}
+}
+
+func TestParseAPI(t *testing.T) {
+	resources, err := Parse("../sample-resources/", "mq")
+	if err != nil {
+		t.Fatalf("Error parsing sample resources: %s", err)
+	}
+	queue, ok := resources["mq/queue"]
+	if !ok {
+		t.Fatal("Expected mq/queue to be parsed.")
+	}
+	if queue.API == nil || queue.API.ID != "mq" || queue.API.Name != "IronMQ" {
+		t.Errorf("Expected mq/queue to belong to the IronMQ API, got %v.", queue.API)
+	}
+	if queue.Parent == nil || queue.Parent.API == nil || queue.Parent.API.ID != "common" {
+		t.Errorf("Expected the parent of mq/queue to belong to the common API.")
+	}
+	for _, resource := range queue.API.Resources {
+		if resource.API != queue.API {
+			t.Errorf("Expected %s to belong to the API that owns it.", resource.ID)
+		}
+	}
+	if queue.GetErrorFormat() != ErrorFormatJarvis {
+		t.Errorf("Expected mq/queue to use its API's %s error format, got %s.", ErrorFormatJarvis, queue.GetErrorFormat())
+	}
+}
+
+var traitAPI = &API{
+	ID: "test",
+	Traits: []Trait{
+		{ID: "identified", Properties: []Property{{ID: "id", Type: "string"}}},
+		{ID: "named", Properties: []Property{{ID: "id", Type: "int"}, {ID: "name", Type: "string"}}},
+		{ID: "paginated", Verbs: []string{"list"}, Params: []Property{{ID: "page", Type: "int"}}},
+	},
+}
+
+func TestTraitExpansion(t *testing.T) {
+	r := Resource{
+		ID:         "queue",
+		Traits:     []string{"identified", "paginated"},
+		Properties: []Property{{ID: "name", Type: "string"}},
+		Interactions: []Interaction{
+			{ID: "list", Verb: "list"},
+			{ID: "get", Verb: "get", Traits: []string{"paginated"}, Params: []Property{{ID: "page", Type: "string"}}},
+			{ID: "delete", Verb: "destroy"},
+		},
+	}
+	err := expandTraits(&r, traitAPI)
+	if err != nil {
+		t.Fatalf("Error expanding traits: %s", err)
+	}
+	if len(r.Properties) != 2 || r.Properties[0].ID != "id" || r.Properties[1].ID != "name" {
+		t.Errorf("Expected properties id and name, got %v.", r.Properties)
+	}
+	if len(r.Interactions[0].Params) != 1 || r.Interactions[0].Params[0].ID != "page" {
+		t.Errorf("Expected list to include the page param, got %v.", r.Interactions[0].Params)
+	}
+	if len(r.Interactions[1].Params) != 1 || r.Interactions[1].Params[0].Type != "string" {
+		t.Errorf("Expected get to override the page param, got %v.", r.Interactions[1].Params)
+	}
+	if len(r.Interactions[2].Params) != 0 {
+		t.Errorf("Expected delete to include no params, got %v.", r.Interactions[2].Params)
+	}
+}
+
+func TestTraitConflicts(t *testing.T) {
+	r := Resource{ID: "queue", Traits: []string{"identified", "named"}}
+	if err := expandTraits(&r, traitAPI); err == nil {
+		t.Error("Expected an error including traits with conflicting definitions of id, got nil.")
+	}
+	r = Resource{ID: "queue", Traits: []string{"identified", "named"}, Properties: []Property{{ID: "id", Type: "string"}}}
+	if err := expandTraits(&r, traitAPI); err != nil {
+		t.Errorf("Expected overriding a conflicting property to resolve the conflict, got %s.", err)
+	}
+	r = Resource{ID: "queue", Traits: []string{"timestamped"}}
+	if err := expandTraits(&r, traitAPI); err == nil {
+		t.Error("Expected an error including an unknown trait, got nil.")
+	}
+}
+
+var paginationParams = map[string][]string{
+	PaginationPage:   []string{"page", "per_page"},
+	PaginationCursor: []string{"cursor", "limit"},
+	PaginationOffset: []string{"offset", "limit"},
+}
+
+func TestPaginationExpansion(t *testing.T) {
+	for style, expected := range paginationParams {
+		r := Resource{
+			ID: "queue",
+			Interactions: []Interaction{{
+				ID:         "list",
+				Verb:       "list",
+				Pagination: &Pagination{Style: style, DefaultSize: 30, MaxSize: 100},
+				Params:     []Property{{ID: "prefix", Type: "string"}},
+			}},
+		}
+		expandPagination(&r)
+		params := r.Interactions[0].Params
+		if len(params) != len(expected)+1 {
+			t.Errorf("Expected %d params for %s pagination, got %d.", len(expected)+1, style, len(params))
+			continue
+		}
+		for i, id := range expected {
+			if params[i].ID != id {
+				t.Errorf("Expected param %d for %s pagination to be %s, got %s.", i, style, id, params[i].ID)
+			}
+		}
+		if params[len(expected)].ID != "prefix" {
+			t.Errorf("Expected declared params to follow the pagination params for %s pagination.", style)
+		}
+	}
+}
+
+func TestVersioningExpansion(t *testing.T) {
+	r := Resource{
+		ID:        "queue",
+		Name:      "Queue",
+		URLSlug:   "name",
+		Versioned: true,
+		Interactions: []Interaction{
+			{ID: "get", Verb: "get"},
+			{ID: "update", Verb: "update"},
+			{ID: "delete", Verb: "destroy"},
+		},
+	}
+	expandVersioning(&r, &API{})
+	expected := map[string]string{"get": IfNoneMatchHeader, "update": IfMatchHeader, "delete": IfMatchHeader}
+	for _, interaction := range r.Interactions {
+		if len(interaction.Params) != 1 || interaction.Params[0].ID != expected[interaction.ID] {
+			t.Errorf("Expected %s to accept the %s header, got %v.", interaction.ID, expected[interaction.ID], interaction.Params)
+		}
+		if interaction.Verb == "destroy" {
+			if interaction.Response != nil {
+				t.Errorf("Expected %s to return no ETag.", interaction.ID)
+			}
+		} else if interaction.Response == nil || len(interaction.Response.Headers) != 1 || interaction.Response.Headers[0].ID != ETagHeader {
+			t.Errorf("Expected %s to return an ETag.", interaction.ID)
+		}
+	}
+	if _, ok := r.GetError(PreconditionFailedError); !ok {
+		t.Errorf("Expected %s to be added to the resource's errors.", PreconditionFailedError)
+	}
+	err := validateResource(r)
+	if err != nil {
+		t.Errorf("Expected the expanded resource to be valid, got %s.", err)
+	}
+}
+
+func TestIdempotencyExpansion(t *testing.T) {
+	r := Resource{
+		ID:      "message",
+		Name:    "Message",
+		URLSlug: "id",
+		Interactions: []Interaction{
+			{ID: "push", Verb: "create", Idempotent: true},
+			{ID: "get", Verb: "get"},
+		},
+	}
+	expandIdempotency(&r, &API{})
+	push := r.Interactions[0]
+	if len(push.Params) != 1 || push.Params[0].ID != IdempotencyKeyHeader || push.Params[0].In != ParamInHeader {
+		t.Errorf("Expected push to accept the %s header, got %v.", IdempotencyKeyHeader, push.Params)
+	}
+	if len(push.Errors) != 1 || push.Errors[0] != IdempotencyKeyReusedError {
+		t.Errorf("Expected push to return %s, got %v.", IdempotencyKeyReusedError, push.Errors)
+	}
+	if len(r.Interactions[1].Params) != 0 || len(r.Interactions[1].Errors) != 0 {
+		t.Errorf("Expected get to be left alone, got %v.", r.Interactions[1])
+	}
+	e, ok := r.GetError(IdempotencyKeyReusedError)
+	if !ok || e.Status != 422 {
+		t.Errorf("Expected %s to be added to the resource's errors, got %v.", IdempotencyKeyReusedError, r.Errors)
+	}
+	err := validateResource(r)
+	if err != nil {
+		t.Errorf("Expected the expanded resource to be valid, got %s.", err)
+	}
+
+	declared := Resource{ID: "message", Name: "Message", URLSlug: "id", Interactions: []Interaction{{ID: "push", Verb: "create", Idempotent: true}}}
+	expandIdempotency(&declared, &API{Errors: []Error{{Code: IdempotencyKeyReusedError, Status: 409}}})
+	if len(declared.Errors) != 0 {
+		t.Errorf("Expected the error the API declares to be used instead of a generated one, got %v.", declared.Errors)
+	}
+	if len(declared.Interactions[0].Errors) != 1 {
+		t.Errorf("Expected push to still return %s, got %v.", IdempotencyKeyReusedError, declared.Interactions[0].Errors)
+	}
+}
+
+func TestLengthMigration(t *testing.T) {
+	properties := []Property{
+		{ID: "name", Type: "string", Minimum: Limit(1), Maximum: Limit(64)},
+		{ID: "ids", Type: "array", Maximum: Limit(100)},
+		{ID: "timeout", Type: "duration", Minimum: Limit(30)},
+	}
+	migrateLengths(properties)
+	if properties[0].MinLength != 1 || properties[0].MaxLength != 64 || properties[0].Minimum != nil || properties[0].Maximum != nil {
+		t.Errorf("Expected the string's minimum and maximum to become its lengths, got %+v.", properties[0])
+	}
+	if properties[1].MaxLength != 100 || properties[1].Maximum != nil {
+		t.Errorf("Expected the array's maximum to become its max_length, got %+v.", properties[1])
+	}
+	if properties[2].Minimum == nil || *properties[2].Minimum != 30 || properties[2].MinLength != 0 {
+		t.Errorf("Expected the duration's minimum to be kept, got %+v.", properties[2])
+	}
+}
+
+func TestNilDefaultMigration(t *testing.T) {
+	r := Resource{
+		Properties: []Property{
+			{ID: "expires_at", Type: "datetime", Default: NilDefault},
+			{ID: "name", Type: "string"},
+			{ID: "size", Type: "int", Default: 10},
+		},
+		Interactions: []Interaction{{ID: "list", Verb: "list", Params: []Property{{ID: "prefix", Type: "string", Default: NilDefault}}}},
+	}
+	migrateResource(&r)
+	if p := r.Properties[0]; p.Default != nil || p.IsRequired() || !p.Nullable {
+		t.Errorf("Expected a nil default to make the property optional and nullable, got %+v.", p)
+	}
+	if p := r.Properties[1]; !p.IsRequired() || p.Nullable {
+		t.Errorf("Expected a property without a default to be required and not nullable, got %+v.", p)
+	}
+	if p := r.Properties[2]; p.IsRequired() {
+		t.Errorf("Expected a property with a default to be optional, got %+v.", p)
+	}
+	if p := r.Interactions[0].Params[0]; p.Default != nil || p.IsRequired() || p.Nullable {
+		t.Errorf("Expected a nil default to make the param optional but not nullable, got %+v.", p)
+	}
+}
+
+func TestValueMigration(t *testing.T) {
+	properties := []Property{{ID: "push_type", Type: "string", Values: []interface{}{
+		"pull",
+		map[interface{}]interface{}{"value": "multicast", "description": "Sent to every subscriber.", "since": 1.1},
+		map[interface{}]interface{}{"value": "broadcast", "deprecated": true},
+	}}}
+	migrateValues(properties)
+	p := properties[0]
+	if len(p.Values) != 3 || p.Values[0] != "pull" || p.Values[1] != "multicast" || p.Values[2] != "broadcast" {
+		t.Errorf("Expected only the values to be left in Values, got %v.", p.Values)
+	}
+	if p.ValueDetails[1].Description != "Sent to every subscriber." || p.ValueDetails[1].Since != "1.1" {
+		t.Errorf("Expected the value's documentation to be kept, got %+v.", p.ValueDetails[1])
+	}
+	if current := p.CurrentValues(); len(current) != 2 || !p.IsDeprecatedValue("broadcast") {
+		t.Errorf("Expected broadcast to be deprecated, got %v.", current)
+	}
+}
+
+func TestUnnamedAPI(t *testing.T) {
+	root, err := ioutil.TempDir("", "jarvis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	err = os.Mkdir(filepath.Join(root, "billing"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(root, "billing", APIFile), []byte("version: \"1\"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := parseAPI(root+string(os.PathSeparator), "billing")
+	if err != nil {
+		t.Fatalf("Error parsing API: %s", err)
+	}
+	if api.Name != "billing" {
+		t.Errorf("Expected an API without a name to be named after its directory, got %q.", api.Name)
+	}
+}
+
+func TestRestrictedParent(t *testing.T) {
+	queue := &Resource{ID: "queue", Name: "Queue", Interactions: []Interaction{{ID: "delete", Verb: "destroy"}, {ID: "get", Verb: "get"}}}
+	subscriber := &Resource{ID: "subscriber", Name: "Subscriber", URLPrefix: "subscribers", Parent: queue, OnParentDelete: OnParentDeleteRestrict}
+	linkRestrictions(subscriber)
+	linkRestrictions(subscriber) // linking again mustn't repeat the error
+	e, ok := queue.GetError("has_subscribers")
+	if !ok || e.Status != 409 || len(queue.Errors) != 1 {
+		t.Errorf("Expected the queue to declare a single has_subscribers conflict, got %v.", queue.Errors)
+	}
+	if len(queue.Interactions[0].Errors) != 1 || queue.Interactions[0].Errors[0] != "has_subscribers" {
+		t.Errorf("Expected destroying the queue to return has_subscribers, got %v.", queue.Interactions[0].Errors)
+	}
+	if len(queue.Interactions[1].Errors) != 0 {
+		t.Errorf("Expected getting the queue not to return has_subscribers, got %v.", queue.Interactions[1].Errors)
+	}
+}
+
+func TestVariantReferences(t *testing.T) {
+	webhook := &Resource{ID: "webhook"}
+	queue := &Resource{
+		ID:         "queue",
+		Properties: []Property{{ID: "push_type", Type: "string", Values: []interface{}{"pull", "push"}}},
+		OneOf:      &OneOf{Discriminator: "push_type", Variants: []Variant{{Value: "push", Properties: []Property{{ID: "webhook_id", Type: "string", References: "mq/webhook"}}}}},
+	}
+	results := map[string]*Resource{"mq/webhook": webhook, "mq/queue": queue}
+	err := linkReferences("mq/queue", queue, results)
+	if err != nil {
+		t.Fatalf("Error linking references: %s", err)
+	}
+	if queue.OneOf.Variants[0].Properties[0].Referenced != webhook {
+		t.Error("Expected the variant property to be linked to the webhook it references.")
+	}
+	queue.OneOf.Variants[0].Properties[0].References = "mq/missing"
+	if err := linkReferences("mq/queue", queue, results); err == nil {
+		t.Error("Expected an error for a variant property referencing an unknown resource.")
+	}
+}
@@ -0,0 +1,43 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	ScopeCollection = "collection" // The interaction acts on the collection of resources, so its URL has no slug
+	ScopeInstance   = "instance"   // The interaction acts on a single resource, identified by the slug in its URL
+)
+
+// GetScope is a helper function that returns what the interaction acts on.
+// It defaults to the collection for lists, creates, and accept_many interactions, and to an instance otherwise.
+func (i Interaction) GetScope() string {
+	if i.Scope != "" {
+		return strings.ToLower(i.Scope)
+	}
+	switch strings.ToLower(i.Verb) {
+	case "list", "create":
+		return ScopeCollection
+	}
+	if i.AcceptMany {
+		return ScopeCollection
+	}
+	return ScopeInstance
+}
+
+func validateScope(interaction Interaction) error {
+	switch strings.ToLower(interaction.Scope) {
+	case "", ScopeCollection:
+	case ScopeInstance:
+		if interaction.AcceptMany {
+			return errors.New("Interaction " + interaction.ID + " accepts many resources, so it must act on the collection.")
+		}
+		if strings.ToLower(interaction.Verb) == "list" {
+			return errors.New("Interaction " + interaction.ID + " is a list, so it must act on the collection.")
+		}
+	default:
+		return errors.New("Interaction " + interaction.ID + " has an unknown scope: " + interaction.Scope)
+	}
+	return nil
+}
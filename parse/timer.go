@@ -0,0 +1,36 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	TimerHide   = "hide"   // The resource is not visible until the duration has elapsed since it was created
+	TimerLock   = "lock"   // The lock the resource holds is released once the duration has elapsed since it was created or updated
+	TimerExpire = "expire" // The resource is deleted once the duration has elapsed since it was created
+	TimerRetry  = "retry"  // Failed deliveries are retried once the duration has elapsed
+)
+
+func validateTimers(r Resource) error {
+	seen := map[string]string{}
+	for _, property := range r.AllProperties() {
+		if property.Timer == "" {
+			continue
+		}
+		timer := strings.ToLower(property.Timer)
+		switch timer {
+		case TimerHide, TimerLock, TimerExpire, TimerRetry:
+		default:
+			return errors.New("Property " + property.ID + " has an unknown timer: " + property.Timer)
+		}
+		if strings.ToLower(property.Type) != "duration" {
+			return errors.New("Property " + property.ID + " has a timer, so it must be a duration.")
+		}
+		if other, ok := seen[timer]; ok {
+			return errors.New("Properties " + other + " and " + property.ID + " both have a " + timer + " timer.")
+		}
+		seen[timer] = property.ID
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// A Trait is a named set of properties and params that resources and interactions can include, instead of repeating them.
+type Trait struct {
+	ID          string     `yaml:"id"`
+	Description string     `yaml:"description"`
+	Properties  []Property `yaml:"properties,omitempty"` // Properties added to resources that include the trait
+	Params      []Property `yaml:"params,omitempty"`     // Params added to interactions that include the trait
+	Verbs       []string   `yaml:"verbs,omitempty"`      // When included by a resource, the verbs of the interactions that get the params. Defaults to all verbs
+}
+
+// GetTrait is a helper function that returns the API's trait with the passed in ID.
+func (api API) GetTrait(id string) (Trait, bool) {
+	for _, trait := range api.Traits {
+		if trait.ID == id {
+			return trait, true
+		}
+	}
+	return Trait{}, false
+}
+
+// appliesTo tests whether the trait's params should be added to interactions with the passed verb when the trait is included by a resource.
+func (t Trait) appliesTo(verb string) bool {
+	if len(t.Verbs) == 0 {
+		return true
+	}
+	for _, v := range t.Verbs {
+		if strings.ToLower(v) == strings.ToLower(verb) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandTraits merges the properties and params of the traits the resource and its interactions include into them.
+// Properties and params the resource or interaction declares itself override those of the same ID from traits.
+func expandTraits(r *Resource, api *API) error {
+	var traits []Trait
+	for _, id := range r.Traits {
+		trait, ok := api.GetTrait(id)
+		if !ok {
+			return errors.New("Resource " + r.ID + " includes unknown trait: " + id)
+		}
+		traits = append(traits, trait)
+	}
+	var props [][]Property
+	for _, trait := range traits {
+		props = append(props, trait.Properties)
+	}
+	properties, err := mergeProperties(r.Properties, traits, props)
+	if err != nil {
+		return errors.New("Resource " + r.ID + ": " + err.Error())
+	}
+	r.Properties = properties
+	for i, interaction := range r.Interactions {
+		var paramTraits []Trait
+		var params [][]Property
+		for _, trait := range traits {
+			if trait.appliesTo(interaction.Verb) {
+				paramTraits = append(paramTraits, trait)
+				params = append(params, trait.Params)
+			}
+		}
+		for _, id := range interaction.Traits {
+			trait, ok := api.GetTrait(id)
+			if !ok {
+				return errors.New("Interaction " + interaction.ID + " of " + r.ID + " includes unknown trait: " + id)
+			}
+			paramTraits = append(paramTraits, trait)
+			params = append(params, trait.Params)
+		}
+		merged, err := mergeProperties(interaction.Params, paramTraits, params)
+		if err != nil {
+			return errors.New("Interaction " + interaction.ID + " of " + r.ID + ": " + err.Error())
+		}
+		r.Interactions[i].Params = merged
+	}
+	return nil
+}
+
+// mergeProperties adds the properties contributed by each trait ahead of the declared properties, skipping any the declared properties override.
+// Two traits contributing different definitions of the same property is a conflict, unless the declared properties override it.
+func mergeProperties(declared []Property, traits []Trait, contributed [][]Property) ([]Property, error) {
+	overridden := map[string]bool{}
+	for _, property := range declared {
+		overridden[property.ID] = true
+	}
+	var results []Property
+	positions := map[string]int{}
+	owners := map[string]string{}
+	for i, properties := range contributed {
+		for _, property := range properties {
+			if overridden[property.ID] {
+				continue
+			}
+			if pos, ok := positions[property.ID]; ok {
+				if !reflect.DeepEqual(results[pos], property) {
+					return results, errors.New("Traits " + owners[property.ID] + " and " + traits[i].ID + " include conflicting definitions of " + property.ID)
+				}
+				continue
+			}
+			positions[property.ID] = len(results)
+			owners[property.ID] = traits[i].ID
+			results = append(results, property)
+		}
+	}
+	return append(results, declared...), nil
+}
@@ -0,0 +1,270 @@
+package parse
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+func validateResource(r Resource) error {
+	err := validateSlug(r)
+	if err != nil {
+		return err
+	}
+	err = validateErrors(r.Errors)
+	if err != nil {
+		return err
+	}
+	err = validateReferences(r)
+	if err != nil {
+		return err
+	}
+	err = validateResourceConstraints(r)
+	if err != nil {
+		return err
+	}
+	err = validateVariants(r)
+	if err != nil {
+		return err
+	}
+	err = validateLifecycle(r)
+	if err != nil {
+		return err
+	}
+	err = validateTimers(r)
+	if err != nil {
+		return err
+	}
+	err = validateCallbacks(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		if e.Field != "" && !hasField(r, e.Field) {
+			return errors.New("Error " + e.Code + " refers to unknown field: " + e.Field)
+		}
+	}
+	for _, interaction := range r.Interactions {
+		for _, param := range interaction.Params {
+			err := validateParam(interaction.ID, param)
+			if err != nil {
+				return err
+			}
+		}
+		err := validateFilters(r, interaction)
+		if err != nil {
+			return err
+		}
+		err = validateFields(r, interaction)
+		if err != nil {
+			return err
+		}
+		err = validateAtomicity(interaction)
+		if err != nil {
+			return err
+		}
+		err = validateIdempotency(interaction)
+		if err != nil {
+			return err
+		}
+		err = validateScope(interaction)
+		if err != nil {
+			return err
+		}
+		err = validateContent(interaction)
+		if err != nil {
+			return err
+		}
+		if interaction.Pagination != nil {
+			err := validatePagination(interaction)
+			if err != nil {
+				return err
+			}
+		}
+		if interaction.Response != nil {
+			err := validateResponse(r, interaction.ID, *interaction.Response)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateAPI checks the API's own definitions, and the references its resources make to them.
+func validateAPI(api *API) error {
+	err := validateErrors(api.Errors)
+	if err != nil {
+		return err
+	}
+	for _, e := range api.Errors {
+		if e.Field != "" {
+			return errors.New("Error " + e.Code + " is declared by the API, so it cannot refer to a field.")
+		}
+	}
+	scopes := map[string]bool{}
+	for _, scheme := range api.Security {
+		err = validateSecurityScheme(api, scheme)
+		if err != nil {
+			return err
+		}
+		for _, scope := range scheme.Scopes {
+			scopes[scope.ID] = true
+		}
+	}
+	for _, r := range api.Resources {
+		for _, interaction := range r.Interactions {
+			for _, code := range interaction.Errors {
+				if _, ok := r.GetError(code); !ok {
+					return errors.New("Interaction " + interaction.ID + " of " + r.ID + " references unknown error: " + code)
+				}
+			}
+			if len(interaction.Scopes) > 0 && len(api.Security) == 0 {
+				return errors.New("Interaction " + interaction.ID + " of " + r.ID + " requires scopes, but the API declares no security schemes.")
+			}
+			for _, scope := range interaction.Scopes {
+				if !scopes[scope] {
+					return errors.New("Interaction " + interaction.ID + " of " + r.ID + " requires unknown scope: " + scope)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateSecurityScheme(api *API, scheme SecurityScheme) error {
+	switch scheme.Type {
+	case SecurityBearer, SecurityOAuth2:
+	case SecurityAPIKey:
+		if scheme.Name == "" {
+			return errors.New("Security scheme " + scheme.ID + " must declare the name of its key.")
+		}
+		if scheme.In != ParamInHeader && scheme.In != ParamInQuery {
+			return errors.New("Security scheme " + scheme.ID + " must be passed in a header or the query string.")
+		}
+	default:
+		return errors.New("Security scheme " + scheme.ID + " has an unknown type: " + scheme.Type)
+	}
+	if len(scheme.Scopes) > 0 && scheme.Type != SecurityOAuth2 {
+		return errors.New("Security scheme " + scheme.ID + " declares scopes, but only oauth2 schemes have scopes.")
+	}
+	for _, code := range []string{scheme.CredentialsError, scheme.ScopeError} {
+		if code == "" {
+			continue
+		}
+		if _, ok := api.GetError(code); !ok {
+			return errors.New("Security scheme " + scheme.ID + " references unknown error: " + code)
+		}
+	}
+	return nil
+}
+
+func validateParam(interaction string, param Property) error {
+	switch param.Location() {
+	case ParamInQuery, ParamInHeader, ParamInCookie:
+	case ParamInPath:
+		if !param.IsRequired() {
+			return errors.New("Path param " + param.ID + " of interaction " + interaction + " is always required, so it cannot have a default value or be optional.")
+		}
+	default:
+		return errors.New("Param " + param.ID + " of interaction " + interaction + " has an unknown location: " + param.In)
+	}
+	return nil
+}
+
+func validateResponse(r Resource, interaction string, response Response) error {
+	if response.Status != 0 && (response.Status < 200 || response.Status > 399) {
+		return errors.New("Interaction " + interaction + " has an invalid response status: " + strconv.Itoa(response.Status))
+	}
+	switch response.Returns {
+	case "", ReturnsResource, ReturnsList, ReturnsResults:
+	case ReturnsNothing:
+		if response.ResourceString != "" || response.Key != "" || len(response.Includes) > 0 {
+			return errors.New("Interaction " + interaction + " returns nothing, but declares a response body.")
+		}
+	case ReturnsShape:
+		if _, ok := r.GetShape(response.Shape); !ok {
+			return errors.New("Interaction " + interaction + " returns unknown shape: " + response.Shape)
+		}
+	default:
+		return errors.New("Interaction " + interaction + " has an unknown response type: " + response.Returns)
+	}
+	if response.Shape != "" && response.Returns != ReturnsShape {
+		return errors.New("Interaction " + interaction + " declares a shape, but does not return it.")
+	}
+	return nil
+}
+
+// validateSlug checks that resources have a slug unless they're singletons, which only support the interactions that don't need one.
+// Singletons can have create interactions that return a different resource, such as a webhook creating messages.
+func validateSlug(r Resource) error {
+	if !r.Singleton {
+		if r.URLSlug == "" {
+			return errors.New("Resource " + r.ID + " must have a url_slug, unless it is a singleton.")
+		}
+		return nil
+	}
+	if r.URLSlug != "" {
+		return errors.New("Resource " + r.ID + " is a singleton, so it cannot have a url_slug.")
+	}
+	for _, interaction := range r.Interactions {
+		if interaction.AcceptMany {
+			return errors.New("Interaction " + interaction.ID + " accepts many, but " + r.ID + " is a singleton.")
+		}
+		switch strings.ToLower(interaction.Verb) {
+		case "get", "update":
+		case "create":
+			if interaction.Response == nil || interaction.Response.ResourceString == "" || interaction.Response.ResourceString == r.QualifiedID() {
+				return errors.New("Interaction " + interaction.ID + " creates a singleton. Singletons can only create other resources.")
+			}
+		default:
+			return errors.New("Interaction " + interaction.ID + " is a " + interaction.Verb + ", but " + r.ID + " is a singleton, which only supports get and update.")
+		}
+	}
+	return nil
+}
+
+func validateErrors(errs []Error) error {
+	codes := map[string]bool{}
+	for _, e := range errs {
+		err := validateError(e)
+		if err != nil {
+			return err
+		}
+		if codes[e.Code] {
+			return errors.New("Error " + e.Code + " is declared more than once.")
+		}
+		codes[e.Code] = true
+	}
+	return nil
+}
+
+func validateError(e Error) error {
+	if e.Code == "" {
+		return errors.New("Errors must have a code.")
+	}
+	if e.Status < 400 || e.Status > 599 {
+		return errors.New("Error " + e.Code + " has an invalid status: " + strconv.Itoa(e.Status))
+	}
+	if e.Status < 500 && e.Action == "" {
+		return errors.New("Error " + e.Code + " must declare the action that resolves it.")
+	}
+	return nil
+}
+
+// hasField tests whether id is a property of the resource or its variants, or a param of one of its interactions.
+func hasField(r Resource, id string) bool {
+	for _, property := range r.AllProperties() {
+		if property.ID == id {
+			return true
+		}
+	}
+	for _, interaction := range r.Interactions {
+		for _, param := range interaction.Params {
+			if param.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// A Value is the documentation of one of the acceptable values of a property.
+type Value struct {
+	Value       interface{}
+	Description string
+	Deprecated  bool
+	Since       string // The version of the API the value was introduced in
+}
+
+// DescribedValues is a helper function that returns the acceptable values of the property, with whatever documentation was declared for them.
+func (p Property) DescribedValues() []Value {
+	if len(p.ValueDetails) == len(p.Values) {
+		return p.ValueDetails
+	}
+	values := make([]Value, 0, len(p.Values))
+	for _, value := range p.Values {
+		values = append(values, Value{Value: value})
+	}
+	return values
+}
+
+// CurrentValues is a helper function that returns the acceptable values of the property that are not deprecated.
+func (p Property) CurrentValues() []interface{} {
+	var values []interface{}
+	for _, value := range p.DescribedValues() {
+		if !value.Deprecated {
+			values = append(values, value.Value)
+		}
+	}
+	return values
+}
+
+// IsDeprecatedValue tests whether the passed value is one of the property's deprecated values.
+func (p Property) IsDeprecatedValue(v interface{}) bool {
+	for _, value := range p.DescribedValues() {
+		if value.Deprecated && sameValue(value.Value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateValues moves the documentation of values declared as objects, with a value and its description, deprecated, and since keys, to the property's ValueDetails, leaving only the value in Values.
+// Properties whose values are all undocumented are left without ValueDetails.
+func migrateValues(properties []Property) {
+	for i, property := range properties {
+		if len(property.Values) == 0 {
+			continue
+		}
+		details := make([]Value, 0, len(property.Values))
+		values := make([]interface{}, 0, len(property.Values))
+		documented := false
+		for _, v := range property.Values {
+			value := Value{Value: v}
+			if m, ok := v.(map[interface{}]interface{}); ok {
+				documented = true
+				value = Value{Value: m["value"]}
+				value.Description, _ = m["description"].(string)
+				value.Deprecated, _ = m["deprecated"].(bool)
+				if since, ok := m["since"]; ok {
+					value.Since = fmt.Sprint(since)
+				}
+			}
+			details = append(details, value)
+			values = append(values, value.Value)
+		}
+		if !documented {
+			continue
+		}
+		properties[i].Values = values
+		properties[i].ValueDetails = details
+	}
+}
+
+func validateValues(property Property) error {
+	if len(property.Values) == 0 {
+		return nil
+	}
+	for _, value := range property.Values {
+		if value == nil {
+			return errors.New("Property " + property.ID + " has a value without a value.")
+		}
+		if !isScalar(value) {
+			return errors.New("Property " + property.ID + " has a value that is an object or a list.")
+		}
+	}
+	if len(property.CurrentValues()) == 0 {
+		return errors.New("All of the values of property " + property.ID + " are deprecated.")
+	}
+	if property.Default != nil && property.IsDeprecatedValue(property.Default) {
+		return errors.New("Property " + property.ID + " defaults to a deprecated value: " + fmt.Sprint(property.Default))
+	}
+	return nil
+}
+
+// isScalar returns whether the value is a single value that can be compared, rather than an object or a list.
+func isScalar(value interface{}) bool {
+	return value == nil || reflect.TypeOf(value).Comparable()
+}
+
+// sameValue returns whether the two values are equal. Objects and lists are never equal to anything, so comparing them can't panic.
+func sameValue(a, b interface{}) bool {
+	return isScalar(a) && isScalar(b) && a == b
+}
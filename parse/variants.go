@@ -0,0 +1,155 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A OneOf is the definition of the variants of a resource, each accepting its own properties, told apart by the value of a discriminator property.
+type OneOf struct {
+	Discriminator string    `yaml:"discriminator"` // The ID of the property whose value selects the variant
+	Variants      []Variant `yaml:"variants"`
+}
+
+// A Variant is the definition of the properties a resource has when its discriminator has a specific value, on top of the properties every variant has.
+type Variant struct {
+	Value       interface{} `yaml:"value"` // The value of the discriminator that selects the variant
+	Description string      `yaml:"description,omitempty"`
+	Properties  []Property  `yaml:"properties,omitempty"`
+}
+
+// A Condition is a rule restricting when a property is allowed, based on the value of another property.
+type Condition struct {
+	Property string        `yaml:"property"`         // The ID of the property the rule depends on
+	In       []interface{} `yaml:"in,omitempty"`     // The property is only allowed when the other property has one of these values
+	NotIn    []interface{} `yaml:"not_in,omitempty"` // The property is only allowed when the other property has none of these values
+}
+
+// Holds tests whether the condition allows its property when the property it depends on has the passed value.
+func (c Condition) Holds(value interface{}) bool {
+	if len(c.In) > 0 {
+		return containsValue(c.In, value)
+	}
+	return !containsValue(c.NotIn, value)
+}
+
+// Describe is a helper function that returns a human-friendly description of the condition.
+func (c Condition) Describe() string {
+	if len(c.In) > 0 {
+		return c.Property + " is " + joinValues(c.In, " or ")
+	}
+	return c.Property + " is not " + joinValues(c.NotIn, " or ")
+}
+
+// GetVariant returns the variant of the resource selected by the passed value of its discriminator.
+func (r Resource) GetVariant(value interface{}) (Variant, bool) {
+	if r.OneOf == nil {
+		return Variant{}, false
+	}
+	for _, variant := range r.OneOf.Variants {
+		if sameValue(variant.Value, value) {
+			return variant, true
+		}
+	}
+	return Variant{}, false
+}
+
+// AllProperties returns the properties every variant of the resource has, followed by the properties of each of its variants.
+func (r Resource) AllProperties() []Property {
+	properties := append([]Property{}, r.Properties...)
+	if r.OneOf == nil {
+		return properties
+	}
+	for _, variant := range r.OneOf.Variants {
+		properties = append(properties, variant.Properties...)
+	}
+	return properties
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if sameValue(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinValues(values []interface{}, sep string) string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, fmt.Sprint(value))
+	}
+	return strings.Join(strs, sep)
+}
+
+func validateVariants(r Resource) error {
+	if r.OneOf != nil {
+		discriminator, ok := r.getProperty(r.OneOf.Discriminator)
+		if !ok {
+			return errors.New("Variants of " + r.ID + " are selected by unknown property: " + r.OneOf.Discriminator)
+		}
+		if len(discriminator.Values) == 0 {
+			return errors.New("Variants of " + r.ID + " are selected by " + discriminator.ID + ", which has no values.")
+		}
+		if discriminator.Nullable {
+			return errors.New("Variants of " + r.ID + " are selected by " + discriminator.ID + ", which can be null.")
+		}
+		seen := map[interface{}]bool{}
+		ids := map[string]bool{}
+		for _, property := range r.Properties {
+			ids[property.ID] = true
+		}
+		for _, variant := range r.OneOf.Variants {
+			if !isScalar(variant.Value) {
+				return errors.New("A variant of " + r.ID + " has a value that is an object or a list.")
+			}
+			if !containsValue(discriminator.Values, variant.Value) {
+				return errors.New("Variant " + fmt.Sprint(variant.Value) + " of " + r.ID + " is not one of the values of " + discriminator.ID)
+			}
+			if seen[variant.Value] {
+				return errors.New("Variant " + fmt.Sprint(variant.Value) + " of " + r.ID + " is declared more than once.")
+			}
+			seen[variant.Value] = true
+			for _, property := range variant.Properties {
+				if ids[property.ID] {
+					return errors.New("Property " + property.ID + " of variant " + fmt.Sprint(variant.Value) + " is already a property of " + r.ID)
+				}
+			}
+		}
+	}
+	for _, property := range r.AllProperties() {
+		if property.When == nil {
+			continue
+		}
+		err := validateCondition(r, property)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCondition(r Resource, property Property) error {
+	c := property.When
+	if c.Property == property.ID {
+		return errors.New("Property " + property.ID + " cannot depend on itself.")
+	}
+	other, ok := r.getProperty(c.Property)
+	if !ok {
+		return errors.New("Property " + property.ID + " depends on unknown property: " + c.Property)
+	}
+	if len(other.Values) == 0 {
+		return errors.New("Property " + property.ID + " depends on " + other.ID + ", which has no values.")
+	}
+	if (len(c.In) > 0) == (len(c.NotIn) > 0) {
+		return errors.New("Property " + property.ID + " must depend on either the values in, or the values not in, " + other.ID)
+	}
+	for _, value := range append(append([]interface{}{}, c.In...), c.NotIn...) {
+		if !containsValue(other.Values, value) {
+			return errors.New("Property " + property.ID + " depends on " + fmt.Sprint(value) + ", which is not one of the values of " + other.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"strings"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// PreconditionFailedError is the code of the error versioned resources return when an If-Match precondition fails.
+const PreconditionFailedError = "precondition_failed"
+
+// expandVersioning adds conditional request headers, ETag response headers, and the precondition_failed error to the interactions of versioned resources.
+// Gets accept If-None-Match; updates and destroys accept If-Match, and fail with precondition_failed when it doesn't match.
+// Params, headers, and errors the resource or its API declare themselves are kept.
+func expandVersioning(r *Resource, api *API) {
+	if !r.Versioned {
+		return
+	}
+	conditional := false
+	for i, interaction := range r.Interactions {
+		switch strings.ToLower(interaction.Verb) {
+		case "get":
+			r.Interactions[i].Params = appendParam(interaction.Params, Property{
+				ID:          IfNoneMatchHeader,
+				Type:        "string",
+				Description: "The ETag of the representation the client already has. If it is still current, 304 Not Modified is returned without a body.",
+				Required:    boolPtr(false),
+				In:          ParamInHeader,
+			})
+		case "update", "destroy":
+			if interaction.AcceptMany {
+				continue // there's no single representation to match against
+			}
+			r.Interactions[i].Params = appendParam(interaction.Params, Property{
+				ID:          IfMatchHeader,
+				Type:        "string",
+				Description: "The ETag of the representation the change was based on. If it is no longer current, the change is not made.",
+				Required:    boolPtr(false),
+				In:          ParamInHeader,
+			})
+			if !hasString(interaction.Errors, PreconditionFailedError) {
+				r.Interactions[i].Errors = append(r.Interactions[i].Errors, PreconditionFailedError)
+			}
+			conditional = true
+		}
+		if strings.ToLower(interaction.Verb) == "destroy" {
+			continue // nothing is returned to tag
+		}
+		if interaction.AcceptMany || strings.ToLower(interaction.Verb) == "list" {
+			continue // lists aren't versioned, only the resources in them
+		}
+		response := Response{}
+		if interaction.Response != nil {
+			response = *interaction.Response
+		}
+		if response.Returns != "" && response.Returns != ReturnsResource {
+			continue
+		}
+		response.Headers = appendParam(response.Headers, Property{
+			ID:          ETagHeader,
+			Type:        "string",
+			Description: "An opaque identifier for the current version of the " + r.Name + ", to send as If-Match or If-None-Match.",
+		})
+		r.Interactions[i].Response = &response
+	}
+	if !conditional {
+		return
+	}
+	addDefaultError(r, api, Error{
+		Code:        PreconditionFailedError,
+		Status:      412,
+		Field:       IfMatchHeader,
+		Description: "The " + r.Name + " has changed since the version in If-Match was retrieved.",
+		Action:      "Retrieve the " + r.Name + " again, and retry the change against its current ETag.",
+	})
+}
+
+// appendParam adds the param to the list, unless one with the same ID is already in it.
+func appendParam(params []Property, param Property) []Property {
+	for _, p := range params {
+		if strings.ToLower(p.ID) == strings.ToLower(param.ID) {
+			return params
+		}
+	}
+	return append(params, param)
+}
@@ -0,0 +1,104 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paddyforan/jarvis/parse"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClockPath is the path of the admin endpoints that control the virtual clock timers are measured against.
+const ClockPath = "_admin/clock"
+
+// hasTimers tests whether any of the passed resources have properties with a timer, which run on the virtual clock.
+func hasTimers(resources []*parse.Resource) bool {
+	for _, resource := range resources {
+		for _, property := range resource.Properties {
+			if property.Timer != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BuildClockEndpoints returns the admin endpoints that read and advance the virtual clock.
+// Timers elapse when the clock is advanced past them, so tests can check them without waiting.
+func BuildClockEndpoints() ([]Endpoint, error) {
+	now := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	current, err := json.Marshal(map[string]interface{}{"clock": map[string]interface{}{"now": now.Format(time.RFC3339)}})
+	if err != nil {
+		return nil, err
+	}
+	request, err := json.Marshal(map[string]interface{}{"seconds": 90})
+	if err != nil {
+		return nil, err
+	}
+	advanced, err := json.Marshal(map[string]interface{}{"clock": map[string]interface{}{"now": now.Add(90 * time.Second).Format(time.RFC3339)}})
+	if err != nil {
+		return nil, err
+	}
+	return []Endpoint{
+		{
+			Verb:           "GET",
+			Path:           ClockPath,
+			Name:           "Get the Clock",
+			Description:    "Retrieve the current time of the virtual clock.",
+			Content:        parse.ContentJSON,
+			Response:       parse.Response{Status: http.StatusOK},
+			SampleResponse: current,
+		},
+		{
+			Verb:           "POST",
+			Path:           ClockPath + "/advance",
+			Name:           "Advance the Clock",
+			Description:    "Move the virtual clock forward by the number of `seconds` in the request, elapsing every timer that runs out in that time. The clock can't be moved backwards.",
+			Content:        parse.ContentJSON,
+			SampleRequest:  request,
+			Response:       parse.Response{Status: http.StatusOK},
+			SampleResponse: advanced,
+		},
+	}, nil
+}
+
+func writeClock(output io.Writer, outputFormat string, resources []*parse.Resource) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		_, err := fmt.Fprint(output, "\n\n# Virtual Clock\n\nTimers are measured against a virtual clock, which only moves when it is advanced with the admin endpoints below. Advancing it by the number of `seconds` in the request elapses every timer that runs out in that time; it can't be moved backwards. The clock drives these timers:\n")
+		if err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			for _, property := range resource.Properties {
+				if property.Timer == "" {
+					continue
+				}
+				_, err = fmt.Fprintf(output, "\n * **%s.%s**: %s", resource.QualifiedID(), property.ID, describeTimer(resource, property))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		endpoints, err := BuildClockEndpoints()
+		if err != nil {
+			return err
+		}
+		for _, endpoint := range endpoints {
+			err = writeEndpoint(output, outputFormat, endpoint)
+			if err != nil {
+				return err
+			}
+			err = writeEndpointResponse(output, outputFormat, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return UnsupportedOutputFormatError
+	}
+}
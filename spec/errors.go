@@ -0,0 +1,81 @@
+package spec
+
+import (
+	"encoding/json"
+	"github.com/paddyforan/jarvis/parse"
+	"strconv"
+	"strings"
+)
+
+// ErrorContentType returns the Content-Type errors are returned with in the specified error format.
+func ErrorContentType(errorFormat string) string {
+	if errorFormat == parse.ErrorFormatProblem {
+		return "application/problem+json"
+	}
+	return "application/json"
+}
+
+// FieldPointer builds a JSON Pointer (RFC 6901) into the request body of the supplied interaction, following the path of properties passed in.
+// For interactions that accept many resources, index is the position of the offending resource in the request.
+func FieldPointer(r parse.Resource, i *parse.Interaction, index int, path ...parse.Property) string {
+	pointer := "/" + escapePointer(r.ID)
+	if i != nil && i.AcceptMany {
+		pointer = "/" + escapePointer(r.URLPrefix) + "/" + strconv.Itoa(index)
+	}
+	for _, property := range path {
+		pointer += "/" + escapePointer(property.ID)
+	}
+	return pointer
+}
+
+func escapePointer(token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	return strings.Replace(token, "/", "~1", -1)
+}
+
+// BuildErrorResponse creates the body of the response returned when the supplied interaction fails with the passed error, in the resource's error format.
+func BuildErrorResponse(r parse.Resource, i *parse.Interaction, e parse.Error) ([]byte, error) {
+	return json.Marshal(buildErrorBody(r, i, 0, e))
+}
+
+// buildErrorBody creates the error representation for the passed error, in the resource's error format.
+// For interactions that accept many resources, index is the position of the resource that caused it.
+func buildErrorBody(r parse.Resource, i *parse.Interaction, index int, e parse.Error) map[string]interface{} {
+	detail := map[string]interface{}{
+		"code":   e.Code,
+		"status": e.Status,
+	}
+	if e.Field != "" {
+		if property, ok := getBodyProperty(r, i, e.Field); ok {
+			detail["pointer"] = FieldPointer(r, i, index, property)
+		} else {
+			detail["param"] = e.Field
+		}
+	}
+	if r.GetErrorFormat() == parse.ErrorFormatProblem {
+		detail["type"] = "urn:jarvis:error:" + e.Code
+		detail["title"] = e.Description
+		if e.Action != "" {
+			detail["detail"] = e.Action
+		}
+		return detail
+	}
+	detail["message"] = e.Description
+	if e.Action != "" {
+		detail["action"] = e.Action
+	}
+	return map[string]interface{}{"errors": []interface{}{detail}}
+}
+
+// getBodyProperty finds the property with the passed ID if it can be sent in the request body of the interaction.
+func getBodyProperty(r parse.Resource, i *parse.Interaction, id string) (parse.Property, bool) {
+	if i == nil || !expectBody(i) {
+		return parse.Property{}, false
+	}
+	for _, property := range r.AllProperties() {
+		if property.ID == id && (property.HasPerm("w") || (strings.ToLower(i.Verb) == "destroy" && property.ID == r.URLSlug)) {
+			return property, true
+		}
+	}
+	return parse.Property{}, false
+}
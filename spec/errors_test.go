@@ -0,0 +1,34 @@
+package spec
+
+import (
+	"github.com/paddyforan/jarvis/parse"
+	"testing"
+)
+
+type pointerPieces struct {
+	interaction *parse.Interaction
+	index       int
+	path        []parse.Property
+}
+
+var pointerResource = parse.Resource{
+	ID:        "message",
+	URLPrefix: "messages",
+}
+
+var testPointers = map[string]pointerPieces{
+	"/message/body":       pointerPieces{&parse.Interaction{Verb: "create"}, 0, []parse.Property{{ID: "body"}}},
+	"/messages/2/body":    pointerPieces{&parse.Interaction{Verb: "create", AcceptMany: true}, 2, []parse.Property{{ID: "body"}}},
+	"/message/a~1b/c~0d":  pointerPieces{&parse.Interaction{Verb: "update"}, 0, []parse.Property{{ID: "a/b"}, {ID: "c~d"}}},
+	"/message":            pointerPieces{&parse.Interaction{Verb: "update"}, 0, nil},
+	"/messages/0/timeout": pointerPieces{&parse.Interaction{Verb: "create", AcceptMany: true}, 0, []parse.Property{{ID: "timeout"}}},
+}
+
+func TestFieldPointers(t *testing.T) {
+	for expected, pieces := range testPointers {
+		pointer := FieldPointer(pointerResource, pieces.interaction, pieces.index, pieces.path...)
+		if pointer != expected {
+			t.Errorf("Expected pointer %s, got %s.", expected, pointer)
+		}
+	}
+}
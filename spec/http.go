@@ -1,11 +1,21 @@
 package spec
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/paddyforan/jarvis/parse"
+	"math"
 	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,16 +29,40 @@ type Endpoint struct {
 	Name           string
 	SampleRequest  []byte
 	SampleResponse []byte
+	Response       parse.Response
+	Errors         []parse.Error
+	ErrorFormat    string
+	SampleError    []byte
+	Security       []parse.SecurityScheme
+	Scopes         []string
+	Pagination     *parse.Pagination
+	Atomicity      string
+	Filters        []parse.Filter
+	Sortable       []string
+	Fields         []string
+	Content        string       // What the request body holds
+	MediaType      string       // The Content-Type of the request body
+	Parts          []parse.Part // The parts of a multipart request body
+	NotModified    bool         // Whether a current If-None-Match gets 304 Not Modified instead of the response
 }
 
-func expectBody(verb string) bool {
-	verb = strings.ToLower(verb)
-	return verb == "create" || verb == "update"
+// ParamsIn returns the endpoint's params that are passed in the specified location.
+func (e Endpoint) ParamsIn(location string) []parse.Property {
+	var params []parse.Property
+	for _, param := range e.Params {
+		if param.Location() == location {
+			params = append(params, param)
+		}
+	}
+	return params
 }
 
-func expectSlug(verb string) bool {
-	verb = strings.ToLower(verb)
-	return verb == "get" || verb == "update" || verb == "destroy"
+func expectBody(i *parse.Interaction) bool {
+	verb := strings.ToLower(i.Verb)
+	if verb == "destroy" {
+		return i.AcceptMany // destroying many resources takes the resources to destroy in the body
+	}
+	return verb == "create" || verb == "update"
 }
 
 func getHTTPVerb(verb string) string {
@@ -51,51 +85,270 @@ func getHTTPVerb(verb string) string {
 // BuildEndpoints examines the resource it is called on and uses its properties to create and return a slice of endpoints.
 func BuildEndpoints(r parse.Resource) ([]Endpoint, error) {
 	endpoints := make([]Endpoint, len(r.Interactions))
+	routes := map[string]string{}
 	for i, interaction := range r.Interactions {
-		if expectBody(interaction.Verb) {
+		if expectBody(&interaction) {
 			req, err := buildSampleRequest(r, &interaction)
 			if err != nil {
 				return endpoints, err
 			}
 			endpoints[i].SampleRequest = req
 		}
-		// TODO: generate sample response body and store it in endpoints[i].SampleResponse
+		endpoints[i].Response = BuildResponse(r, &interaction)
+		endpoints[i].Content = interaction.GetContent()
+		endpoints[i].MediaType = interaction.GetMediaType()
+		endpoints[i].Parts = interaction.Parts
+		endpoints[i].Pagination = interaction.Pagination
+		endpoints[i].Filters = interaction.Filters
+		endpoints[i].Sortable = interaction.Sortable
+		if interaction.Fields {
+			endpoints[i].Fields = r.ReadableProperties()
+		}
+		if interaction.AcceptMany {
+			endpoints[i].Atomicity = interaction.GetAtomicity()
+		}
+		resp, err := buildSampleResponse(r, &interaction, endpoints[i].Response)
+		if err != nil {
+			return endpoints, err
+		}
+		endpoints[i].SampleResponse = resp
 		endpoints[i].Verb = getHTTPVerb(interaction.Verb)
 		endpoints[i].Description = interaction.Description
 		endpoints[i].Name = interaction.Name
 		endpoints[i].Params = interaction.Params
+		endpoints[i].NotModified = isConditionalGet(&interaction)
 		endpoints[i].Path = BuildPath(r, &interaction)
+		route := endpoints[i].Verb + " " + endpoints[i].Path
+		if other, ok := routes[route]; ok {
+			return endpoints, errors.New("Interactions " + other + " and " + interaction.ID + " of " + r.ID + " are both " + route)
+		}
+		routes[route] = interaction.ID
+		for _, code := range interaction.Errors {
+			e, ok := r.GetError(code)
+			if !ok {
+				return endpoints, errors.New("Interaction " + interaction.ID + " of " + r.ID + " references unknown error: " + code)
+			}
+			endpoints[i].Errors = append(endpoints[i].Errors, e)
+		}
+		if r.API != nil && len(r.API.Security) > 0 {
+			endpoints[i].Security = r.API.Security
+			endpoints[i].Scopes = interaction.Scopes
+			for _, scheme := range r.API.Security {
+				codes := []string{scheme.CredentialsError}
+				if len(interaction.Scopes) > 0 {
+					codes = append(codes, scheme.ScopeError)
+				}
+				for _, code := range codes {
+					if code == "" {
+						continue
+					}
+					e, ok := r.GetError(code)
+					if !ok {
+						return endpoints, errors.New("Security scheme " + scheme.ID + " references unknown error: " + code)
+					}
+					endpoints[i].Errors = appendError(endpoints[i].Errors, e)
+				}
+			}
+		}
+		endpoints[i].ErrorFormat = r.GetErrorFormat()
+		if len(endpoints[i].Errors) > 0 {
+			sample, err := BuildErrorResponse(r, &interaction, endpoints[i].Errors[0])
+			if err != nil {
+				return endpoints, err
+			}
+			endpoints[i].SampleError = sample
+		}
 	}
 	return endpoints, nil
 }
 
+// appendError adds e to errs, unless an error with the same code is already present.
+func appendError(errs []parse.Error, e parse.Error) []parse.Error {
+	for _, existing := range errs {
+		if existing.Code == e.Code {
+			return errs
+		}
+	}
+	return append(errs, e)
+}
+
+// isConditionalGet returns whether the interaction is a get that accepts If-None-Match, and so can respond 304 Not Modified.
+func isConditionalGet(i *parse.Interaction) bool {
+	if strings.ToLower(i.Verb) != "get" {
+		return false
+	}
+	for _, param := range i.Params {
+		if param.Location() == parse.ParamInHeader && strings.EqualFold(param.ID, parse.IfNoneMatchHeader) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildResponse examines the supplied interaction and returns the response it declares, inferring anything it does not declare from its verb.
+func BuildResponse(r parse.Resource, i *parse.Interaction) parse.Response {
+	var response parse.Response
+	if i.Response != nil {
+		response = *i.Response
+	}
+	if response.Returns == "" {
+		response.Returns = getDefaultReturns(i)
+	}
+	if response.Resource == nil && (response.Returns == parse.ReturnsResource || response.Returns == parse.ReturnsList) {
+		response.Resource = &r
+	}
+	if response.Key == "" {
+		switch response.Returns {
+		case parse.ReturnsResource:
+			response.Key = response.Resource.ID
+		case parse.ReturnsList:
+			response.Key = response.Resource.URLPrefix
+		case parse.ReturnsShape:
+			response.Key = response.Shape
+		case parse.ReturnsResults:
+			response.Key = parse.ResultsKey
+		}
+	}
+	if response.Status == 0 {
+		response.Status = getDefaultStatus(i.Verb, response.Returns)
+	}
+	return response
+}
+
+func getDefaultReturns(i *parse.Interaction) string {
+	if i.AcceptMany && i.GetAtomicity() == parse.AtomicityPerItem {
+		return parse.ReturnsResults
+	}
+	switch strings.ToLower(i.Verb) {
+	case "destroy":
+		return parse.ReturnsNothing
+	case "list":
+		return parse.ReturnsList
+	}
+	if i.AcceptMany {
+		return parse.ReturnsList
+	}
+	return parse.ReturnsResource
+}
+
+func getDefaultStatus(verb, returns string) int {
+	if returns == parse.ReturnsNothing {
+		return http.StatusNoContent
+	}
+	if returns == parse.ReturnsResults {
+		return http.StatusMultiStatus
+	}
+	if strings.ToLower(verb) == "create" {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
 // BuildPathPieces examines the resource it is called on (and that resource's parents) to create the pieces of the URL endpoint for the supplied interaction.
 func BuildPathPieces(r parse.Resource, i *parse.Interaction) []string {
 	var pieces []string
 	if r.Parent != nil {
 		pieces = append(pieces, BuildPathPieces(*r.Parent, nil)...)
-		if !r.ParentIsCollection {
+		if !r.ParentIsCollection && !r.Parent.Singleton {
 			pieces = append(pieces, "{"+r.Parent.URLSlug+"}")
 		}
 	}
 	pieces = append(pieces, r.URLPrefix)
-	if i == nil || !expectSlug(i.Verb) || i.AcceptMany {
+	if i == nil {
 		return pieces
 	}
-	pieces = append(pieces, "{"+r.URLSlug+"}")
+	if i.GetScope() != parse.ScopeCollection && !r.Singleton {
+		pieces = append(pieces, "{"+r.URLSlug+"}")
+	}
+	// path params that don't describe one of the slugs get a segment of their own, in the order they're declared
+	for _, param := range i.Params {
+		if param.Location() == parse.ParamInPath && !hasPiece(pieces, "{"+param.ID+"}") {
+			pieces = append(pieces, "{"+param.ID+"}")
+		}
+	}
 	return pieces
 }
 
+func hasPiece(pieces []string, piece string) bool {
+	for _, p := range pieces {
+		if p == piece {
+			return true
+		}
+	}
+	return false
+}
+
 // BuildPath examines the resource it is called on (and that resource's parents) to create the URL endpoint for the supplied interaction.
 func BuildPath(r parse.Resource, i *parse.Interaction) string {
 	return strings.Join(BuildPathPieces(r, i), "/")
 }
 
+// BinarySample stands in for the raw bytes of binary request and response bodies in samples.
+const BinarySample = "<binary data>"
+
 func buildSampleRequest(r parse.Resource, i *parse.Interaction) ([]byte, error) {
-	data := make([]byte, 0)
-	if !expectBody(i.Verb) {
-		return data, nil
+	if !expectBody(i) {
+		return []byte{}, nil
 	}
+	switch i.GetContent() {
+	case parse.ContentBinary:
+		return []byte(BinarySample), nil
+	case parse.ContentText:
+		text, err := genRandomString(16, 64)
+		return []byte(text), err
+	case parse.ContentMultipart:
+		return buildSampleMultipart(r, i)
+	}
+	return buildSampleJSONRequest(r, i)
+}
+
+// buildSampleMultipart builds a multipart/form-data request body holding a sample of each of the interaction's parts.
+// The JSON part holds the same document a JSON request body would.
+func buildSampleMultipart(r parse.Resource, i *parse.Interaction) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	writer := multipart.NewWriter(buf)
+	err := writer.SetBoundary("boundary")
+	if err != nil {
+		return buf.Bytes(), err
+	}
+	for _, part := range i.Parts {
+		var body []byte
+		disposition := fmt.Sprintf("form-data; name=%q", part.Name)
+		switch part.GetContent() {
+		case parse.ContentJSON:
+			body, err = buildSampleJSONRequest(r, i)
+		case parse.ContentBinary:
+			body = []byte(BinarySample)
+			disposition += fmt.Sprintf("; filename=%q", part.Name)
+		case parse.ContentText:
+			var text string
+			text, err = genRandomString(16, 64)
+			body = []byte(text)
+		}
+		if err != nil {
+			return buf.Bytes(), err
+		}
+		if len(body) == 0 {
+			continue
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", disposition)
+		header.Set("Content-Type", part.GetMediaType())
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return buf.Bytes(), err
+		}
+		_, err = w.Write(body)
+		if err != nil {
+			return buf.Bytes(), err
+		}
+	}
+	err = writer.Close()
+	return bytes.Replace(buf.Bytes(), []byte("\r\n"), []byte("\n"), -1), err // samples are read, not sent
+}
+
+func buildSampleJSONRequest(r parse.Resource, i *parse.Interaction) ([]byte, error) {
+	data := make([]byte, 0)
 	if len(r.Properties) == 0 {
 		return data, nil
 	}
@@ -105,18 +358,25 @@ func buildSampleRequest(r parse.Resource, i *parse.Interaction) ([]byte, error)
 		num = 3
 	}
 	for iter := 0; iter < num; iter++ {
-		resource := map[string]interface{}{} // ALL the maps!
+		var properties []parse.Property
 		for _, property := range r.Properties {
-			if !property.HasPerm("w") {
+			if strings.ToLower(i.Verb) == "destroy" && property.ID != r.URLSlug {
+				continue // resources to destroy are identified by their slug alone
+			}
+			if strings.ToLower(i.Verb) != "destroy" && !property.HasPerm("w") {
 				continue // if we can't write the property, don't include it in the request
 			}
-			val, err := genRandomValue(&property)
+			properties = append(properties, property)
+		}
+		resource, err := genSampleInput(properties)
+		if err != nil {
+			return data, err
+		}
+		if strings.ToLower(i.Verb) != "destroy" {
+			err = genSampleVariant(r, resource, "w", genSampleInput)
 			if err != nil {
 				return data, err
 			}
-			if val != nil {
-				resource[property.ID] = val
-			}
 		}
 		if len(resource) == 0 {
 			continue
@@ -135,8 +395,229 @@ func buildSampleRequest(r parse.Resource, i *parse.Interaction) ([]byte, error)
 	return json.Marshal(request)
 }
 
+func buildSampleResponse(r parse.Resource, i *parse.Interaction, response parse.Response) ([]byte, error) {
+	data := make([]byte, 0)
+	switch response.GetContent() {
+	case parse.ContentBinary:
+		return []byte(BinarySample), nil
+	case parse.ContentText:
+		text, err := genRandomString(16, 64)
+		return []byte(text), err
+	}
+	body := map[string]interface{}{}
+	switch response.Returns {
+	case parse.ReturnsResource:
+		resource, err := genSampleResource(*response.Resource)
+		if err != nil {
+			return data, err
+		}
+		body[response.Key] = resource
+	case parse.ReturnsList:
+		resources := []map[string]interface{}{}
+		for iter := 0; iter < 3; iter++ {
+			resource, err := genSampleResource(*response.Resource)
+			if err != nil {
+				return data, err
+			}
+			resources = append(resources, resource)
+		}
+		body[response.Key] = resources
+		if i.Pagination != nil {
+			next, err := genSampleNextPage(*i.Pagination)
+			if err != nil {
+				return data, err
+			}
+			body[parse.PaginationKey] = map[string]interface{}{i.Pagination.NextKey(): next}
+		}
+	case parse.ReturnsShape:
+		shape, _ := r.GetShape(response.Shape)
+		obj, err := genSampleObject(shape.Properties)
+		if err != nil {
+			return data, err
+		}
+		body[response.Key] = obj
+	case parse.ReturnsResults:
+		results, err := genSampleResults(r, i)
+		if err != nil {
+			return data, err
+		}
+		body[response.Key] = results
+	}
+	for _, included := range response.IncludedResources {
+		resource, err := genSampleResource(*included)
+		if err != nil {
+			return data, err
+		}
+		body[included.ID] = resource
+	}
+	if len(body) == 0 {
+		return data, nil
+	}
+	return json.Marshal(body)
+}
+
+// BuildCallbackPayload creates a sample of the body of the passed callback, enveloped like a response returning the resource it sends.
+func BuildCallbackPayload(c parse.Callback) ([]byte, error) {
+	if c.Payload == nil {
+		return []byte{}, nil
+	}
+	resource, err := genSampleResource(*c.Payload)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(map[string]interface{}{c.Payload.ID: resource})
+}
+
+// genSampleResults creates a sample result for each of three resources sent to a per_item interaction.
+// If the interaction can fail, the last one fails with its first error.
+func genSampleResults(r parse.Resource, i *parse.Interaction) ([]map[string]interface{}, error) {
+	single := *i
+	single.AcceptMany = false
+	single.Response = nil
+	item := BuildResponse(r, &single)
+	var results []map[string]interface{}
+	for index := 0; index < 3; index++ {
+		result := map[string]interface{}{"index": index}
+		if index == 2 && len(i.Errors) > 0 {
+			e, ok := r.GetError(i.Errors[0])
+			if !ok {
+				return results, errors.New("Interaction " + i.ID + " of " + r.ID + " references unknown error: " + i.Errors[0])
+			}
+			result["status"] = e.Status
+			if r.GetErrorFormat() == parse.ErrorFormatProblem {
+				result["error"] = buildErrorBody(r, i, index, e)
+			} else {
+				result["errors"] = buildErrorBody(r, i, index, e)["errors"]
+			}
+			results = append(results, result)
+			continue
+		}
+		result["status"] = item.Status
+		if item.Returns == parse.ReturnsResource {
+			resource, err := genSampleResource(*item.Resource)
+			if err != nil {
+				return results, err
+			}
+			result[item.Key] = resource
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func genSampleNextPage(p parse.Pagination) (interface{}, error) {
+	switch strings.ToLower(p.Style) {
+	case parse.PaginationPage:
+		return 1, nil
+	case parse.PaginationCursor:
+		return genRandomString(16, 16)
+	case parse.PaginationOffset:
+		if p.DefaultSize != 0 {
+			return p.DefaultSize, nil
+		}
+		return 3, nil
+	}
+	return nil, nil
+}
+
+func getReadableProperties(r parse.Resource) []parse.Property {
+	var properties []parse.Property
+	for _, property := range r.Properties {
+		if property.HasPerm("r") {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// genSampleResource generates a sample of the resource as it's returned, including the properties of its variant.
+func genSampleResource(r parse.Resource) (map[string]interface{}, error) {
+	obj, err := genSampleObject(getReadableProperties(r))
+	if err != nil {
+		return obj, err
+	}
+	err = genSampleVariant(r, obj, "r", genSampleObject)
+	return obj, err
+}
+
+// genSampleVariant adds the properties with the passed permission of the variant selected by the sample's discriminator,
+// then removes the properties whose conditions the sample's values don't meet.
+func genSampleVariant(r parse.Resource, obj map[string]interface{}, perm string, gen func([]parse.Property) (map[string]interface{}, error)) error {
+	properties := r.Properties
+	if r.OneOf != nil {
+		variant, _ := r.GetVariant(getSampleValue(r, obj, r.OneOf.Discriminator))
+		var permitted []parse.Property
+		for _, property := range variant.Properties {
+			if property.HasPerm(perm) {
+				permitted = append(permitted, property)
+			}
+		}
+		values, err := gen(permitted)
+		if err != nil {
+			return err
+		}
+		for id, value := range values {
+			obj[id] = value
+		}
+		properties = append(append([]parse.Property{}, properties...), variant.Properties...)
+	}
+	for _, property := range properties {
+		if property.When != nil && !property.When.Holds(getSampleValue(r, obj, property.When.Property)) {
+			delete(obj, property.ID)
+		}
+	}
+	return nil
+}
+
+// getSampleValue returns the value of the property in the sample, or its default if the sample leaves it out.
+func getSampleValue(r parse.Resource, obj map[string]interface{}, id string) interface{} {
+	if value, ok := obj[id]; ok {
+		return value
+	}
+	for _, property := range r.Properties {
+		if property.ID == id {
+			return property.Default
+		}
+	}
+	return nil
+}
+
+// genSampleInput generates a sample of the properties as they're sent in a request, where optional properties can be left out.
+func genSampleInput(properties []parse.Property) (map[string]interface{}, error) {
+	obj := map[string]interface{}{} // ALL the maps!
+	for _, property := range properties {
+		val, err := genRandomValue(&property)
+		if err != nil {
+			return obj, err
+		}
+		if val != nil {
+			obj[property.ID] = val
+		}
+	}
+	return obj, nil
+}
+
+func genSampleObject(properties []parse.Property) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	for _, property := range properties {
+		if property.Default != nil {
+			obj[property.ID] = property.Default // responses always include every property
+			continue
+		}
+		if !property.Nullable {
+			property.Required = nil // only nullable properties are returned without a value
+		}
+		val, err := genRandomValue(&property)
+		if err != nil {
+			return obj, err
+		}
+		obj[property.ID] = val
+	}
+	return obj, nil
+}
+
 func genRandomValue(p *parse.Property) (interface{}, error) {
-	if p.Default != nil {
+	if !p.IsRequired() {
 		include, err := genRandomBool()
 		if err != nil {
 			return nil, err
@@ -144,32 +625,67 @@ func genRandomValue(p *parse.Property) (interface{}, error) {
 		if !include {
 			return nil, nil
 		}
-		return p.Default, nil
+		if p.Default != nil {
+			return p.Default, nil
+		}
 	}
 	if p.Values != nil {
-		return pickRandomValue(p.Values)
+		return pickRandomValue(p.CurrentValues()) // samples shouldn't encourage deprecated values
 	}
 	p.Type = strings.ToLower(p.Type)
 	switch p.Type {
 	case "string":
-		return genRandomString(p.Minimum, p.Maximum)
+		return genRandomString(getLengthBounds(p))
 	case "bytes":
-		return genRandomBytes(p.Minimum, p.Maximum)
-	case "duration":
-		return genRandomInt(p.Minimum, p.Maximum)
+		return genRandomBytes(getLengthBounds(p))
+	case "duration", "int":
+		return genRandomInteger(p)
 	case "datetime":
 		return genRandomTime(p.Minimum, p.Maximum)
-	case "int":
-		return genRandomInt(p.Minimum, p.Maximum)
-	case "Float":
-		return genRandomFloat(p.Minimum, p.Maximum)
+	case "float":
+		return genRandomFloat(p)
 	case "boolean":
 		return genRandomBool()
+	case "array":
+		return genRandomArray(p)
+	case "map":
+		return genRandomMap(p)
 	}
 	// TODO: throw error
 	return nil, nil
 }
 
+// getLengthBounds returns the range, with an exclusive upper bound, that the length of the property's value should be generated in.
+func getLengthBounds(p *parse.Property) (int, int) {
+	min, max := p.MinLength, p.MaxLength+1
+	if p.MaxLength == 0 {
+		max = min + 32
+	}
+	return min, max
+}
+
+// getIntBounds returns the range, with an exclusive upper bound, that the property's whole number value should be generated in.
+func getIntBounds(p *parse.Property) (int, int) {
+	min, max := 0, 32
+	if p.Minimum != nil {
+		min = int(math.Ceil(*p.Minimum))
+		if p.ExclusiveMinimum && float64(min) == *p.Minimum {
+			min++
+		}
+		max = min + 32
+	}
+	if p.Maximum != nil {
+		max = int(math.Floor(*p.Maximum)) + 1
+		if p.ExclusiveMaximum && float64(max-1) == *p.Maximum {
+			max--
+		}
+		if p.Minimum == nil && min >= max {
+			min = max - 32
+		}
+	}
+	return min, max
+}
+
 func genRandomString(min, max int) (string, error) {
 	b, err := genRandomBytes(min, max)
 	if err != nil {
@@ -178,7 +694,7 @@ func genRandomString(min, max int) (string, error) {
 	en := base64.StdEncoding
 	d := make([]byte, en.EncodedLen(len(b)))
 	en.Encode(d, b)
-	return string(d), nil
+	return string(d[:len(b)]), nil // keep the string as long as the number of bytes generated, so it respects the length bounds
 }
 
 func genRandomBytes(min, max int) ([]byte, error) {
@@ -195,7 +711,7 @@ func genRandomInt(min, max int) (int64, error) {
 	if max == 0 && min == 0 {
 		max = 32
 	}
-	if max-min == 0 {
+	if max-min <= 0 {
 		return int64(min), nil
 	}
 	bigMax := big.NewInt(int64(max))
@@ -207,18 +723,231 @@ func genRandomInt(min, max int) (int64, error) {
 	return i.Int64(), err
 }
 
-func genRandomTime(min, max int) (time.Time, error) {
+// genRandomInteger generates a whole number within the property's bounds, and a multiple of its multiple_of.
+func genRandomInteger(p *parse.Property) (int64, error) {
+	min, max := getIntBounds(p)
+	step := int(p.MultipleOf)
+	if step <= 1 {
+		return genRandomInt(min, max)
+	}
+	first, last, err := multiplesBetween(p, float64(min), float64(max-1), float64(step))
+	if err != nil {
+		return 0, err
+	}
+	n, err := genRandomInt(first, last+1)
+	return n * int64(step), err
+}
+
+// multiplesBetween returns the range of whole numbers that, times step, fall within min and max and the property's exclusive bounds.
+// Unbounded sides are extended so 32 multiples are available.
+func multiplesBetween(p *parse.Property, min, max, step float64) (int, int, error) {
+	first := int(math.Ceil(min / step))
+	if p.ExclusiveMinimum && float64(first)*step <= *p.Minimum {
+		first++
+	}
+	last := int(math.Floor(max / step))
+	if p.ExclusiveMaximum && float64(last)*step >= *p.Maximum {
+		last--
+	}
+	if p.Maximum == nil {
+		last = first + 31
+	} else if p.Minimum == nil {
+		first = last - 31
+	}
+	if last < first {
+		return 0, 0, errors.New("Property " + p.ID + " has no multiple of " + strconv.FormatFloat(step, 'f', -1, 64) + " within its bounds.")
+	}
+	return first, last, nil
+}
+
+func genRandomTime(min, max *float64) (time.Time, error) {
 	// TODO
 	var t time.Time
 	return t, nil
 }
 
-func genRandomFloat(min, max int) (float64, error) {
-	// TODO
-	var f float64
+// genRandomFloat generates a number within the property's bounds, and a multiple of its multiple_of.
+func genRandomFloat(p *parse.Property) (float64, error) {
+	min, max := 0.0, 32.0
+	if p.Minimum != nil {
+		min, max = *p.Minimum, *p.Minimum+32
+	}
+	if p.Maximum != nil {
+		max = *p.Maximum
+		if p.Minimum == nil && min >= max {
+			min = max - 32
+		}
+	}
+	if p.MultipleOf > 0 {
+		first, last, err := multiplesBetween(p, min, max, p.MultipleOf)
+		if err != nil {
+			return 0, err
+		}
+		n, err := genRandomInt(first, last+1)
+		return float64(n) * p.MultipleOf, err
+	}
+	const precision = 1 << 53
+	n, err := genRandomInt(0, precision)
+	if err != nil {
+		return 0, err
+	}
+	f := min + (max-min)*float64(n)/precision
+	if (p.ExclusiveMinimum && f <= min) || (p.ExclusiveMaximum && f >= max) {
+		f = (min + max) / 2
+	}
 	return f, nil
 }
 
+func genRandomArray(p *parse.Property) ([]interface{}, error) {
+	values := []interface{}{}
+	if p.ValueType == "" {
+		return values, nil
+	}
+	num := 3
+	if p.MaxLength != 0 && p.MaxLength < num {
+		num = p.MaxLength
+	}
+	if p.MinLength > num {
+		num = p.MinLength
+	}
+	seen := map[string]bool{}
+	for iter := 0; len(values) < num && iter < num*10; iter++ {
+		val, err := genRandomValue(&parse.Property{Type: p.ValueType})
+		if err != nil {
+			return values, err
+		}
+		if p.UniqueItems {
+			key := fmt.Sprint(val)
+			if seen[key] {
+				continue // try again, rather than repeat a value
+			}
+			seen[key] = true
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+func genRandomMap(p *parse.Property) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	num := 3
+	if p.MaxKeys != 0 && p.MaxKeys < num {
+		num = p.MaxKeys
+	}
+	var pattern *regexp.Regexp
+	if p.KeyPattern != "" {
+		var err error
+		pattern, err = regexp.Compile(p.KeyPattern)
+		if err != nil {
+			return values, err
+		}
+	}
+	for iter := 0; len(values) < num && iter < num*10; iter++ {
+		var key string
+		var err error
+		if pattern == nil {
+			key, err = genRandomString(4, 12)
+		} else {
+			key, err = genRandomMatch(p.KeyPattern)
+		}
+		if err != nil {
+			return values, err
+		}
+		if _, ok := values[key]; ok || (pattern != nil && !pattern.MatchString(key)) {
+			continue // try again, rather than repeat a key or use one that doesn't match
+		}
+		val, err := genRandomValue(&parse.Property{Type: p.ValueType})
+		if err != nil {
+			return values, err
+		}
+		values[key] = val
+	}
+	return values, nil
+}
+
+// genRandomMatch generates a string matching the passed regular expression, keeping to printable ASCII where it can.
+func genRandomMatch(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var out []rune
+	err = genRandomRunes(re.Simplify(), &out)
+	return string(out), err
+}
+
+func genRandomRunes(re *syntax.Regexp, out *[]rune) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		*out = append(*out, re.Rune...)
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return errors.New("Can't generate a value for an empty character class.")
+		}
+		i, err := genRandomInt(0, len(re.Rune)/2)
+		if err != nil {
+			return err
+		}
+		lo, hi := re.Rune[i*2], re.Rune[i*2+1]
+		if lo < ' ' && hi >= ' ' {
+			lo = ' '
+		}
+		if lo <= '~' && hi > '~' {
+			hi = '~'
+		}
+		r, err := genRandomInt(int(lo), int(hi)+1)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, rune(r))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		r, err := genRandomInt('a', 'z'+1)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, rune(r))
+	case syntax.OpCapture:
+		return genRandomRunes(re.Sub[0], out)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			err := genRandomRunes(sub, out)
+			if err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		i, err := genRandomInt(0, len(re.Sub))
+		if err != nil {
+			return err
+		}
+		return genRandomRunes(re.Sub[i], out)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, 3
+		switch re.Op {
+		case syntax.OpPlus:
+			min = 1
+		case syntax.OpQuest:
+			max = 1
+		case syntax.OpRepeat:
+			min, max = re.Min, re.Max
+			if max == -1 {
+				max = min + 3
+			}
+		}
+		n, err := genRandomInt(min, max+1)
+		if err != nil {
+			return err
+		}
+		for iter := int64(0); iter < n; iter++ {
+			err = genRandomRunes(re.Sub[0], out)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil // anchors and empty matches add nothing
+}
+
 func genRandomBool() (bool, error) {
 	i, err := genRandomInt(0, 2)
 	return i == 1, err
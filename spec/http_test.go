@@ -1,111 +1,160 @@
 package spec
 
 import (
+	"bytes"
+	"github.com/paddyforan/jarvis/parse"
+	"math"
+	"regexp"
+	"strings"
 	"testing"
 )
 
 type endpointPieces struct {
-	resource    *Resource
-	interaction *Interaction
+	resource    *parse.Resource
+	interaction *parse.Interaction
 }
 
 var (
-	rootResource = &Resource{
+	rootResource = &parse.Resource{
 		ID:        "rootResource",
 		URLPrefix: "roots",
 		URLSlug:   "id",
 	}
-	childResource = &Resource{
+	childResource = &parse.Resource{
 		ID:        "childResource",
 		URLPrefix: "children",
 		URLSlug:   "name",
 		Parent:    rootResource,
 	}
-	orphanResource = &Resource{
+	orphanResource = &parse.Resource{
 		ID:                 "orphanResource",
 		URLPrefix:          "orphans",
 		URLSlug:            "birthday",
 		Parent:             rootResource,
 		ParentIsCollection: true,
 	}
-	grandchildResource = &Resource{
+	grandchildResource = &parse.Resource{
 		ID:        "grandchildResource",
 		URLPrefix: "grandchildren",
 		URLSlug:   "id",
 		Parent:    childResource,
 	}
-	orphanChildResource = &Resource{
+	orphanChildResource = &parse.Resource{
 		ID:        "orphanChildResource",
 		URLPrefix: "orphanchildren",
 		URLSlug:   "id",
 		Parent:    orphanResource,
 	}
-	childOrphanResource = &Resource{
+	childOrphanResource = &parse.Resource{
 		ID:                 "childOrphanResource",
 		URLPrefix:          "orphans",
 		URLSlug:            "name",
 		Parent:             childResource,
 		ParentIsCollection: true,
 	}
-	orphanOrphanResource = &Resource{
+	orphanOrphanResource = &parse.Resource{
 		ID:                 "orphanOrphanResource",
 		URLPrefix:          "orphans",
 		URLSlug:            "name",
 		Parent:             orphanResource,
 		ParentIsCollection: true,
 	}
+	singletonResource = &parse.Resource{
+		ID:        "singletonResource",
+		URLPrefix: "settings",
+		Singleton: true,
+		Parent:    rootResource,
+	}
+	singletonChildResource = &parse.Resource{
+		ID:        "singletonChildResource",
+		URLPrefix: "rules",
+		URLSlug:   "id",
+		Parent:    singletonResource,
+	}
 )
 
 var (
-	listInteraction = &Interaction{
+	listInteraction = &parse.Interaction{
 		ID:          "list",
 		Name:        "list",
 		Verb:        "list",
 		Description: "list resources",
 		AcceptMany:  false,
 	}
-	getInteraction = &Interaction{
+	getInteraction = &parse.Interaction{
 		ID:          "get",
 		Name:        "get",
 		Verb:        "get",
 		Description: "get resource",
 		AcceptMany:  false,
 	}
-	updateInteraction = &Interaction{
+	updateInteraction = &parse.Interaction{
 		ID:          "update",
 		Name:        "update",
 		Verb:        "update",
 		Description: "update resource",
 		AcceptMany:  false,
 	}
-	createInteraction = &Interaction{
+	createInteraction = &parse.Interaction{
 		ID:          "create",
 		Name:        "create",
 		Verb:        "create",
 		Description: "create resource",
 		AcceptMany:  false,
 	}
-	createManyInteraction = &Interaction{
+	createManyInteraction = &parse.Interaction{
 		ID:          "createMany",
 		Name:        "create many",
 		Verb:        "create",
 		Description: "create resources",
 		AcceptMany:  true,
 	}
-	destroyInteraction = &Interaction{
+	destroyInteraction = &parse.Interaction{
 		ID:          "destroy",
 		Name:        "destroy",
 		Verb:        "destroy",
 		Description: "destroy resource",
 		AcceptMany:  false,
 	}
-	destroyManyInteraction = &Interaction{
+	destroyManyInteraction = &parse.Interaction{
 		ID:          "destroyMany",
 		Name:        "destroy many",
 		Verb:        "destroy",
 		Description: "destroy resources",
 		AcceptMany:  true,
 	}
+	clearInteraction = &parse.Interaction{
+		ID:          "clear",
+		Name:        "clear",
+		Verb:        "destroy",
+		Description: "destroy every resource",
+		Scope:       parse.ScopeCollection,
+	}
+	createInstanceInteraction = &parse.Interaction{
+		ID:          "createInstance",
+		Name:        "create instance",
+		Verb:        "create",
+		Description: "create resource with a chosen slug",
+		Scope:       parse.ScopeInstance,
+	}
+	createPerItemInteraction = &parse.Interaction{
+		ID:          "createPerItem",
+		Name:        "create per item",
+		Verb:        "create",
+		Description: "create resources independently",
+		AcceptMany:  true,
+		Atomicity:   parse.AtomicityPerItem,
+	}
+	getVersionInteraction = &parse.Interaction{
+		ID:          "getVersion",
+		Name:        "get version",
+		Verb:        "get",
+		Description: "get a version of the resource",
+		Params: []parse.Property{
+			{ID: "id", Type: "string", Description: "The ID of the resource.", In: parse.ParamInPath},
+			{ID: "version", Type: "int", Description: "The version to get.", In: parse.ParamInPath},
+		},
+	}
 )
 
 var testPaths = map[endpointPieces][]string{
@@ -164,11 +213,23 @@ var testPaths = map[endpointPieces][]string{
 	endpointPieces{orphanChildResource, destroyManyInteraction}:  []string{"roots", "orphans", "{birthday}", "orphanchildren"},
 	endpointPieces{childOrphanResource, destroyManyInteraction}:  []string{"roots", "{id}", "children", "orphans"},
 	endpointPieces{orphanOrphanResource, destroyManyInteraction}: []string{"roots", "orphans", "orphans"},
+
+	endpointPieces{rootResource, clearInteraction}:           []string{"roots"},
+	endpointPieces{childResource, clearInteraction}:          []string{"roots", "{id}", "children"},
+	endpointPieces{rootResource, createInstanceInteraction}:  []string{"roots", "{id}"},
+	endpointPieces{childResource, createInstanceInteraction}: []string{"roots", "{id}", "children", "{name}"},
+	endpointPieces{rootResource, getVersionInteraction}:      []string{"roots", "{id}", "{version}"},
+	endpointPieces{childResource, getVersionInteraction}:     []string{"roots", "{id}", "children", "{name}", "{version}"},
+
+	endpointPieces{singletonResource, getInteraction}:       []string{"roots", "{id}", "settings"},
+	endpointPieces{singletonResource, updateInteraction}:    []string{"roots", "{id}", "settings"},
+	endpointPieces{singletonChildResource, listInteraction}: []string{"roots", "{id}", "settings", "rules"},
+	endpointPieces{singletonChildResource, getInteraction}:  []string{"roots", "{id}", "settings", "rules", "{id}"},
 }
 
 func TestPathBuilding(t *testing.T) {
 	for endpoint, pieces := range testPaths {
-		pathPieces := endpoint.resource.BuildPathPieces(endpoint.interaction)
+		pathPieces := BuildPathPieces(*endpoint.resource, endpoint.interaction)
 		if len(pathPieces) != len(pieces) {
 			t.Errorf("Error building path for %s. Expected %d pieces in the path, got %d pieces.", endpoint.resource.ID+"#"+endpoint.interaction.ID, len(pathPieces), len(pieces))
 		}
@@ -179,3 +240,258 @@ func TestPathBuilding(t *testing.T) {
 		}
 	}
 }
+
+var testResponses = map[*parse.Interaction]parse.Response{
+	listInteraction:          parse.Response{Status: 200, Returns: parse.ReturnsList, Key: "roots"},
+	getInteraction:           parse.Response{Status: 200, Returns: parse.ReturnsResource, Key: "rootResource"},
+	updateInteraction:        parse.Response{Status: 200, Returns: parse.ReturnsResource, Key: "rootResource"},
+	createInteraction:        parse.Response{Status: 201, Returns: parse.ReturnsResource, Key: "rootResource"},
+	createManyInteraction:    parse.Response{Status: 201, Returns: parse.ReturnsList, Key: "roots"},
+	destroyInteraction:       parse.Response{Status: 204, Returns: parse.ReturnsNothing},
+	destroyManyInteraction:   parse.Response{Status: 204, Returns: parse.ReturnsNothing},
+	createPerItemInteraction: parse.Response{Status: 207, Returns: parse.ReturnsResults, Key: "results"},
+}
+
+func TestDefaultResponses(t *testing.T) {
+	for interaction, expected := range testResponses {
+		response := BuildResponse(*rootResource, interaction)
+		if response.Status != expected.Status {
+			t.Errorf("Expected %s to respond with status %d, got %d.", interaction.ID, expected.Status, response.Status)
+		}
+		if response.Returns != expected.Returns {
+			t.Errorf("Expected %s to return %s, got %s.", interaction.ID, expected.Returns, response.Returns)
+		}
+		if response.Key != expected.Key {
+			t.Errorf("Expected %s to use the key %s, got %s.", interaction.ID, expected.Key, response.Key)
+		}
+	}
+}
+
+var boundedProperties = []parse.Property{
+	{ID: "name", Type: "string", MinLength: 1, MaxLength: 4},
+	{ID: "timeout", Type: "duration", Minimum: parse.Limit(30), Maximum: parse.Limit(40), MultipleOf: 5},
+	{ID: "ratio", Type: "float", Minimum: parse.Limit(0.5), Maximum: parse.Limit(2.5), ExclusiveMaximum: true},
+	{ID: "size", Type: "int", Minimum: parse.Limit(0), Maximum: parse.Limit(1), ExclusiveMinimum: true},
+	{ID: "retries", Type: "int", Minimum: parse.Limit(3), Maximum: parse.Limit(17), MultipleOf: 5},
+	{ID: "weight", Type: "float", Minimum: parse.Limit(0.3), Maximum: parse.Limit(1.25), MultipleOf: 0.25, ExclusiveMinimum: true, ExclusiveMaximum: true},
+}
+
+func TestBoundedValues(t *testing.T) {
+	for iter := 0; iter < 50; iter++ {
+		for _, property := range boundedProperties {
+			val, err := genRandomValue(&property)
+			if err != nil {
+				t.Fatalf("Error generating %s: %s", property.ID, err)
+			}
+			switch v := val.(type) {
+			case string:
+				if len(v) < property.MinLength || len(v) > property.MaxLength {
+					t.Errorf("Expected %s to be between %d and %d characters, got %q.", property.ID, property.MinLength, property.MaxLength, v)
+				}
+			case int64:
+				if !withinBounds(property, float64(v)) {
+					t.Errorf("Expected %s to be within its bounds, got %d.", property.ID, v)
+				}
+				if property.MultipleOf != 0 && v%int64(property.MultipleOf) != 0 {
+					t.Errorf("Expected %s to be a multiple of %v, got %d.", property.ID, property.MultipleOf, v)
+				}
+			case float64:
+				if !withinBounds(property, v) {
+					t.Errorf("Expected %s to be within its bounds, got %v.", property.ID, v)
+				}
+				if property.MultipleOf != 0 && math.Remainder(v, property.MultipleOf) != 0 {
+					t.Errorf("Expected %s to be a multiple of %v, got %v.", property.ID, property.MultipleOf, v)
+				}
+			default:
+				t.Errorf("Unexpected value generated for %s: %v", property.ID, val)
+			}
+		}
+	}
+}
+
+var variantResource = parse.Resource{
+	ID:      "queue",
+	URLSlug: "name",
+	Properties: []parse.Property{
+		{ID: "name", Type: "string", Permissions: []string{"r", "w"}},
+		{ID: "push_type", Type: "string", Values: []interface{}{"pull", "multicast"}, Permissions: []string{"r", "w"}},
+		{ID: "retries", Type: "int", Default: 3, Permissions: []string{"r", "w"}, When: &parse.Condition{Property: "push_type", NotIn: []interface{}{"pull"}}},
+	},
+	OneOf: &parse.OneOf{Discriminator: "push_type", Variants: []parse.Variant{
+		{Value: "pull", Properties: []parse.Property{{ID: "default_timeout", Type: "duration", Permissions: []string{"r", "w"}}}},
+	}},
+}
+
+func withinBounds(p parse.Property, v float64) bool {
+	if v < *p.Minimum || (p.ExclusiveMinimum && v == *p.Minimum) {
+		return false
+	}
+	return v < *p.Maximum || (!p.ExclusiveMaximum && v == *p.Maximum)
+}
+
+func TestNoMultipleInBounds(t *testing.T) {
+	property := parse.Property{ID: "step", Type: "float", Minimum: parse.Limit(1), Maximum: parse.Limit(1.5), MultipleOf: 0.5, ExclusiveMinimum: true, ExclusiveMaximum: true}
+	if _, err := genRandomValue(&property); err == nil {
+		t.Error("Expected an error when no multiple fits within the bounds.")
+	}
+}
+
+func TestVariantSamples(t *testing.T) {
+	for iter := 0; iter < 50; iter++ {
+		sample, err := genSampleResource(variantResource)
+		if err != nil {
+			t.Fatalf("Error generating sample: %s", err)
+		}
+		_, timeout := sample["default_timeout"]
+		_, retries := sample["retries"]
+		switch sample["push_type"] {
+		case "pull":
+			if !timeout || retries {
+				t.Errorf("Expected a pull queue to have a default_timeout and no retries, got %v.", sample)
+			}
+		case "multicast":
+			if timeout || !retries {
+				t.Errorf("Expected a multicast queue to have retries and no default_timeout, got %v.", sample)
+			}
+		default:
+			t.Errorf("Unexpected push_type in %v.", sample)
+		}
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	property := parse.Property{ID: "metadata", Type: "map", ValueType: "int", KeyPattern: `^[a-z][a-z0-9_]{0,7}$`, MaxKeys: 2}
+	pattern := regexp.MustCompile(property.KeyPattern)
+	for iter := 0; iter < 50; iter++ {
+		val, err := genRandomValue(&property)
+		if err != nil {
+			t.Fatalf("Error generating %s: %s", property.ID, err)
+		}
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected %s to be a map, got %v.", property.ID, val)
+		}
+		if len(m) > property.MaxKeys {
+			t.Errorf("Expected at most %d keys, got %v.", property.MaxKeys, m)
+		}
+		for key, value := range m {
+			if !pattern.MatchString(key) {
+				t.Errorf("Expected %q to match %s.", key, property.KeyPattern)
+			}
+			if _, ok := value.(int64); !ok {
+				t.Errorf("Expected the value of %q to be an int, got %v.", key, value)
+			}
+		}
+	}
+}
+
+func TestMultipartSample(t *testing.T) {
+	r := parse.Resource{
+		ID:         "attachment",
+		URLSlug:    "id",
+		Properties: []parse.Property{{ID: "name", Type: "string", Permissions: []string{"r", "w"}}},
+	}
+	i := &parse.Interaction{ID: "upload", Verb: "create", Content: parse.ContentMultipart, Parts: []parse.Part{
+		{Name: "metadata"},
+		{Name: "file", Content: parse.ContentBinary, MediaType: "image/png"},
+	}}
+	sample, err := buildSampleRequest(r, i)
+	if err != nil {
+		t.Fatalf("Error generating sample: %s", err)
+	}
+	for _, expected := range []string{
+		`Content-Disposition: form-data; name="metadata"`,
+		`"attachment":`,
+		`Content-Disposition: form-data; name="file"; filename="file"`,
+		"Content-Type: image/png",
+		BinarySample,
+		"--boundary--",
+	} {
+		if !strings.Contains(string(sample), expected) {
+			t.Errorf("Expected the sample to contain %q, got:\n%s", expected, sample)
+		}
+	}
+}
+
+func TestDuplicateRoutes(t *testing.T) {
+	resource := *rootResource
+	resource.Interactions = []parse.Interaction{
+		{ID: "clear", Verb: "destroy", AcceptMany: true},
+		{ID: "purge", Verb: "destroy", Scope: parse.ScopeCollection},
+	}
+	if _, err := BuildEndpoints(resource); err == nil {
+		t.Error("Expected an error for two interactions on the same method and path.")
+	}
+	resource.Interactions[1].Scope = parse.ScopeInstance
+	if _, err := BuildEndpoints(resource); err != nil {
+		t.Errorf("Expected interactions on different paths to be accepted, got %s.", err)
+	}
+}
+
+func TestNotModifiedResponse(t *testing.T) {
+	ifNoneMatch := parse.Property{ID: parse.IfNoneMatchHeader, Type: "string", In: parse.ParamInHeader}
+	resource := *rootResource
+	resource.Interactions = []parse.Interaction{{ID: "get", Verb: "get"}}
+	endpoints, err := BuildEndpoints(resource)
+	if err != nil {
+		t.Fatalf("Error building endpoints: %s", err)
+	}
+	if endpoints[0].NotModified {
+		t.Error("Expected a get without If-None-Match not to declare a 304 response.")
+	}
+	resource.Interactions = []parse.Interaction{{ID: "get", Verb: "get", Params: []parse.Property{ifNoneMatch}}}
+	endpoints, err = BuildEndpoints(resource)
+	if err != nil {
+		t.Fatalf("Error building endpoints: %s", err)
+	}
+	if !endpoints[0].NotModified {
+		t.Error("Expected a get accepting If-None-Match to declare a 304 response.")
+	}
+	resource.Interactions[0].Params = []parse.Property{{ID: "if-none-match", Type: "string", In: "Header"}}
+	endpoints, err = BuildEndpoints(resource)
+	if err != nil {
+		t.Fatalf("Error building endpoints: %s", err)
+	}
+	if !endpoints[0].NotModified {
+		t.Error("Expected a get declaring its own if-none-match header to declare a 304 response.")
+	}
+	var output bytes.Buffer
+	err = writeEndpointResponse(&output, "markdown", endpoints[0])
+	if err != nil {
+		t.Fatalf("Error writing response: %s", err)
+	}
+	if !strings.Contains(output.String(), "304 Not Modified") {
+		t.Errorf("Expected the response to document 304 Not Modified, got %q.", output.String())
+	}
+}
+
+func TestClockEndpoints(t *testing.T) {
+	if hasTimers([]*parse.Resource{rootResource}) {
+		t.Error("Expected a resource without timers not to need the clock.")
+	}
+	timed := &parse.Resource{ID: "message", Properties: []parse.Property{{ID: "delay", Type: "duration", Timer: parse.TimerHide}}}
+	if !hasTimers([]*parse.Resource{rootResource, timed}) {
+		t.Error("Expected a resource with a timer to need the clock.")
+	}
+	endpoints, err := BuildClockEndpoints()
+	if err != nil {
+		t.Fatalf("Error building clock endpoints: %s", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].Path != ClockPath || endpoints[1].Path != ClockPath+"/advance" || len(endpoints[1].SampleRequest) == 0 {
+		t.Errorf("Expected endpoints to get and advance the clock, got %+v.", endpoints)
+	}
+}
+
+func TestPropertyLimits(t *testing.T) {
+	property := parse.Property{ID: "retention", Type: "duration", Minimum: parse.Limit(0.5), Maximum: parse.Limit(2592000), MultipleOf: 3600}
+	var output bytes.Buffer
+	err := writeProperty(&output, rootResource, property)
+	if err != nil {
+		t.Fatalf("Error writing property: %s", err)
+	}
+	for _, expected := range []string{"**Maximum Value**: 2592000", "**Minimum Value**: 0.5", "**Multiple Of**: 3600"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected %q in %q.", expected, output.String())
+		}
+	}
+}
@@ -7,40 +7,171 @@ import (
   "fmt"
 	"github.com/paddyforan/jarvis/parse"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 var UnsupportedOutputFormatError = errors.New("Unsupported output format.")
 
 func Generate(outputFormat string, output io.WriteCloser, resources []*parse.Resource) error {
 	defer output.Close()
+	// group the resources by the API they belong to, keeping the order they were passed in
+	var apis []*parse.API
+	apiResources := map[*parse.API][]*parse.Resource{}
 	for _, resource := range resources {
 		if resource == nil {
 			continue
 		}
-		endpoints, err := BuildEndpoints(*resource)
+		if _, ok := apiResources[resource.API]; !ok {
+			apis = append(apis, resource.API)
+		}
+		apiResources[resource.API] = append(apiResources[resource.API], resource)
+	}
+	for _, api := range apis {
+		if api != nil && hasInteractions(apiResources[api]) {
+			err := writeAPIHeader(output, outputFormat, api)
+			if err != nil {
+				return err
+			}
+		}
+		for _, resource := range apiResources[api] {
+			err := generateResource(output, outputFormat, resource)
+			if err != nil {
+				return err
+			}
+		}
+		if api != nil && hasTimers(apiResources[api]) {
+			err := writeClock(output, outputFormat, apiResources[api])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasInteractions(resources []*parse.Resource) bool {
+	for _, resource := range resources {
+		if len(resource.Interactions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func generateResource(output io.Writer, outputFormat string, resource *parse.Resource) error {
+	endpoints, err := BuildEndpoints(*resource)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) < 1 {
+		return nil
+	}
+	err = writeResourceHeader(output, outputFormat, resource, resource.QualifiedID())
+	if err != nil {
+		return err
+	}
+	err = writeProperties(output, outputFormat, resource)
+	if err != nil {
+		return err
+	}
+	err = writeVariants(output, outputFormat, resource)
+	if err != nil {
+		return err
+	}
+	err = writeParentDelete(output, outputFormat, resource)
+	if err != nil {
+		return err
+	}
+	err = writeLifecycle(output, outputFormat, resource)
+	if err != nil {
+		return err
+	}
+	err = writeCallbacks(output, outputFormat, resource)
+	if err != nil {
+		return err
+	}
+	for _, endpoint := range endpoints {
+		err = writeEndpoint(output, outputFormat, endpoint)
 		if err != nil {
 			return err
 		}
-		if len(endpoints) < 1 {
-			continue
+		err = writeEndpointResponse(output, outputFormat, endpoint)
+		if err != nil {
+			return err
 		}
-		err = writeResourceHeader(output, outputFormat, resource, resource.ID)
+		err = writeEndpointErrors(output, outputFormat, endpoint)
 		if err != nil {
 			return err
 		}
-		err = writeProperties(output, outputFormat, resource)
+	}
+	return nil
+}
+
+func writeAPIHeader(output io.Writer, outputFormat string, api *parse.API) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		_, err := fmt.Fprintf(output, "\n# %s (%s)\n%s", api.Name, api.ID, api.Description)
 		if err != nil {
 			return err
 		}
-		for _, endpoint := range endpoints {
-			err = writeEndpoint(output, outputFormat, endpoint)
+		if api.Version != "" || api.BaseURL != "" || api.Contact != nil || len(api.Security) > 0 {
+			_, err = fmt.Fprint(output, "\n")
 			if err != nil {
 				return err
 			}
 		}
+		if api.Version != "" {
+			_, err = fmt.Fprintf(output, "\n * **Version**: %s", api.Version)
+			if err != nil {
+				return err
+			}
+		}
+		if api.BaseURL != "" {
+			_, err = fmt.Fprintf(output, "\n * **Base URL**: %s", api.BaseURL)
+			if err != nil {
+				return err
+			}
+		}
+		if api.Contact != nil {
+			contact := api.Contact.Name
+			if api.Contact.Email != "" {
+				contact += " <" + api.Contact.Email + ">"
+			}
+			if api.Contact.URL != "" {
+				contact += " " + api.Contact.URL
+			}
+			_, err = fmt.Fprintf(output, "\n * **Contact**: %s", strings.TrimSpace(contact))
+			if err != nil {
+				return err
+			}
+		}
+		if len(api.Security) > 0 {
+			_, err = fmt.Fprint(output, "\n * **Authentication**:")
+			if err != nil {
+				return err
+			}
+		}
+		for _, scheme := range api.Security {
+			_, err = fmt.Fprintf(output, "\n\t * **%s** *(%s)*: %s", scheme.ID, scheme.Type, scheme.Description)
+			if err != nil {
+				return err
+			}
+			for _, scope := range scheme.Scopes {
+				_, err = fmt.Fprintf(output, "\n\t\t * **%s**: %s", scope.ID, scope.Description)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = fmt.Fprint(output, "\n")
+		return err
+	default:
+		return UnsupportedOutputFormatError
 	}
-	return nil
 }
 
 func writeResourceHeader(output io.Writer, outputFormat string, resource *parse.Resource, id string) error {
@@ -48,12 +179,51 @@ func writeResourceHeader(output io.Writer, outputFormat string, resource *parse.
 	switch outputFormat {
 	case "markdown":
 		_, err := fmt.Fprintf(output, "\n# %s (%s)\n%s", resource.Name, id, resource.Description)
+		if err != nil {
+			return err
+		}
+		if resource.Singleton {
+			_, err = fmt.Fprintf(output, "\n\nThe %s is a singleton: there is only one at its URL, so it has no slug.", resource.Name)
+		}
 		return err
 	default:
 		return UnsupportedOutputFormatError
 	}
 }
 
+// writeValueTable writes the values of a property, with their descriptions and deprecation, as a table.
+// The version each value was introduced in is only included if any value declares one.
+func writeValueTable(output io.Writer, values []parse.Value) error {
+	since := false
+	for _, value := range values {
+		since = since || value.Since != ""
+	}
+	header := "\n\n\t\t| Value | Description |\n\t\t| --- | --- |"
+	if since {
+		header = "\n\n\t\t| Value | Description | Since |\n\t\t| --- | --- | --- |"
+	}
+	_, err := fmt.Fprint(output, header)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		description := strings.Replace(value.Description, "|", "\\|", -1)
+		if value.Deprecated {
+			description = strings.TrimSpace("**Deprecated.** " + description)
+		}
+		row := fmt.Sprintf("\n\t\t| `%v` | %s |", value.Value, description)
+		if since {
+			row += " " + value.Since + " |"
+		}
+		_, err = fmt.Fprint(output, row)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprint(output, "\n")
+	return err
+}
+
 func writeProperties(output io.Writer, outputFormat string, resource *parse.Resource) error {
 	outputFormat = strings.ToLower(outputFormat)
 	switch outputFormat {
@@ -65,30 +235,330 @@ func writeProperties(output io.Writer, outputFormat string, resource *parse.Reso
       }
     }
 		for _, property := range resource.Properties {
-			_, err := fmt.Fprintf(output, "\n * **%s** *(%s)*: %s", property.ID, property.Type, property.Description)
+			err := writeProperty(output, resource, property)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return UnsupportedOutputFormatError
+	}
+	return nil
+}
+
+// writeProperty writes the description and constraints of one of the resource's properties as a list item.
+func writeProperty(output io.Writer, resource *parse.Resource, property parse.Property) error {
+	_, err := fmt.Fprintf(output, "\n * **%s** *(%s)*: %s", property.ID, property.Type, property.Description)
+	if err != nil {
+		return err
+	}
+	if len(property.Values) > 0 {
+		_, err = fmt.Fprint(output, "\n\t * **Possible Values**:")
+		if len(property.ValueDetails) > 0 {
+			err = writeValueTable(output, property.ValueDetails)
+		} else {
+			for _, value := range property.Values {
+				_, err = fmt.Fprintf(output, "\n\t\t * %v", value)
+			}
+		}
+	}
+	if property.Default != nil {
+		_, err = fmt.Fprintf(output, "\n\t * **Default Value**: %v", property.Default)
+	}
+	if property.HasPerm("w") && property.IsRequired() {
+		_, err = fmt.Fprint(output, "\n\t * **Required**: must be set when creating the resource")
+	}
+	if property.Nullable {
+		_, err = fmt.Fprint(output, "\n\t * **Nullable**: can be null")
+	}
+	if property.Maximum != nil {
+		_, err = fmt.Fprintf(output, "\n\t * **Maximum Value**: %s%s", formatNumber(*property.Maximum), exclusive(property.ExclusiveMaximum))
+	}
+	if property.Minimum != nil {
+		_, err = fmt.Fprintf(output, "\n\t * **Minimum Value**: %s%s", formatNumber(*property.Minimum), exclusive(property.ExclusiveMinimum))
+	}
+	if property.MultipleOf != 0 {
+		_, err = fmt.Fprintf(output, "\n\t * **Multiple Of**: %s", formatNumber(property.MultipleOf))
+	}
+	if property.MaxLength != 0 {
+		_, err = fmt.Fprintf(output, "\n\t * **Maximum Length**: %v", property.MaxLength)
+	}
+	if property.MinLength != 0 {
+		_, err = fmt.Fprintf(output, "\n\t * **Minimum Length**: %v", property.MinLength)
+	}
+	if property.UniqueItems {
+		_, err = fmt.Fprint(output, "\n\t * **Unique Items**: values cannot be repeated")
+	}
+	if strings.ToLower(property.Type) == "bytes" {
+		_, err = fmt.Fprint(output, "\n\t * **Encoding**: base64")
+	}
+	if strings.ToLower(property.Type) == "map" {
+		_, err = fmt.Fprintf(output, "\n\t * **Value Type**: %s", property.ValueType)
+	}
+	if property.KeyPattern != "" {
+		_, err = fmt.Fprintf(output, "\n\t * **Key Pattern**: `%s`", property.KeyPattern)
+	}
+	if property.MaxKeys != 0 {
+		_, err = fmt.Fprintf(output, "\n\t * **Maximum Keys**: %v", property.MaxKeys)
+	}
+	if property.Timer != "" {
+		_, err = fmt.Fprintf(output, "\n\t * **Timer**: %s", describeTimer(resource, property))
+	}
+	if property.Referenced != nil {
+		_, err = fmt.Fprintf(output, "\n\t * **References**: %s", resourceLink(resource, property.Referenced))
+	}
+	if property.When != nil {
+		_, err = fmt.Fprintf(output, "\n\t * **Only Allowed When**: %s", property.When.Describe())
+	}
+	return err
+}
+
+func writeVariants(output io.Writer, outputFormat string, resource *parse.Resource) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		if resource.OneOf == nil {
+			return nil
+		}
+		_, err := fmt.Fprintf(output, "\n\n## Variants\n\nEach %s also has the properties of the variant selected by its `%s`.", resource.Name, resource.OneOf.Discriminator)
+		if err != nil {
+			return err
+		}
+		for _, variant := range resource.OneOf.Variants {
+			_, err = fmt.Fprintf(output, "\n\n### %s: %v\n", resource.OneOf.Discriminator, variant.Value)
 			if err != nil {
 				return err
 			}
-			if len(property.Values) > 0 {
-				_, err = fmt.Fprint(output, "\n\t * **Possible Values**:")
-				for _, value := range property.Values {
-					_, err = fmt.Fprintf(output, "\n\t\t * %v", value)
+			if variant.Description != "" {
+				_, err = fmt.Fprintf(output, "\n%s\n", variant.Description)
+				if err != nil {
+					return err
 				}
 			}
-			if property.Default != nil {
-				_, err = fmt.Fprintf(output, "\n\t * **Default Value**: %v", property.Default)
+			for _, property := range variant.Properties {
+				err = writeProperty(output, resource, property)
+				if err != nil {
+					return err
+				}
 			}
-			if property.Maximum != 0 {
-				_, err = fmt.Fprintf(output, "\n\t * **Maximum Value**: %v", property.Maximum)
+		}
+		return nil
+	default:
+		return UnsupportedOutputFormatError
+	}
+}
+
+func exclusive(isExclusive bool) string {
+	if isExclusive {
+		return " (exclusive)"
+	}
+	return ""
+}
+
+// formatNumber writes a limit in plain decimal notation, so large values aren't printed as exponents.
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func writeParentDelete(output io.Writer, outputFormat string, resource *parse.Resource) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		if resource.Parent == nil || resource.ParentIsCollection || resource.OnParentDelete == "" {
+			return nil // only describe what was declared
+		}
+		parent := resourceLink(resource, resource.Parent)
+		var err error
+		switch resource.GetOnParentDelete() {
+		case parse.OnParentDeleteCascade:
+			_, err = fmt.Fprintf(output, "\n\nDestroying a %s also destroys every %s under it.", parent, resource.Name)
+		case parse.OnParentDeleteRestrict:
+			_, err = fmt.Fprintf(output, "\n\nA %s cannot be destroyed while a %s exists under it.", parent, resource.Name)
+		case parse.OnParentDeleteOrphan:
+			_, err = fmt.Fprintf(output, "\n\nDestroying a %s leaves every %s under it in place.", parent, resource.Name)
+		}
+		return err
+	default:
+		return UnsupportedOutputFormatError
+	}
+}
+
+func writeLifecycle(output io.Writer, outputFormat string, resource *parse.Resource) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		if resource.Lifecycle == nil {
+			return nil
+		}
+		l := resource.Lifecycle
+		_, err := fmt.Fprintf(output, "\n\n## Lifecycle\n\nThe state of a %s is held in `%s`.\n", resource.Name, l.Property)
+		if err != nil {
+			return err
+		}
+		for _, state := range l.States {
+			_, err = fmt.Fprintf(output, "\n * **%s**", state.ID)
+			if err != nil {
+				return err
+			}
+			if state.Initial {
+				_, err = fmt.Fprint(output, " *(initial)*")
+			} else if state.Final {
+				_, err = fmt.Fprint(output, " *(final)*")
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(output, ": %s", state.Description)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(output, "\n\n```mermaid\nstateDiagram-v2\n    [*] --> %s", l.InitialState().ID)
+		if err != nil {
+			return err
+		}
+		for _, transition := range l.Transitions {
+			for _, from := range transition.From {
+				_, err = fmt.Fprintf(output, "\n    %s --> %s: %s", from, transition.To, transitionName(transition))
+				if err != nil {
+					return err
+				}
 			}
-			if property.Minimum != 0 {
-				_, err = fmt.Fprintf(output, "\n\t * **Minimum Value**: %v", property.Minimum)
+		}
+		for _, state := range l.States {
+			if state.Final {
+				_, err = fmt.Fprintf(output, "\n    %s --> [*]", state.ID)
+				if err != nil {
+					return err
+				}
 			}
 		}
+		_, err = fmt.Fprint(output, "\n```")
+		if err != nil {
+			return err
+		}
+		for _, transition := range l.Transitions {
+			if transition.Error == "" {
+				continue
+			}
+			_, err = fmt.Fprintf(output, "\n\n%s returns `%s` unless the %s is %s.", transitionName(transition), transition.Error, resource.Name, strings.Join(transition.From, " or "))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return UnsupportedOutputFormatError
+	}
+}
+
+func writeCallbacks(output io.Writer, outputFormat string, resource *parse.Resource) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		for _, callback := range resource.Callbacks {
+			_, err := fmt.Fprintf(output, "\n\n## %s (callback)\n\n%s", callback.Name, callback.Description)
+			if err != nil {
+				return err
+			}
+			trigger := callback.Trigger
+			if callback.TriggerResource != nil {
+				_, interactionID := callback.TriggerParts()
+				if interaction, ok := callback.TriggerResource.GetInteraction(interactionID); ok {
+					trigger = interaction.Name + " (" + resourceLink(resource, callback.TriggerResource) + ")"
+				}
+			}
+			_, err = fmt.Fprintf(output, "\n\nSent after %s succeeds.\n\n### Request\n\nPOST {%s}", trigger, callback.URL)
+			if err != nil {
+				return err
+			}
+			sample, err := BuildCallbackPayload(callback)
+			if err != nil {
+				return err
+			}
+			if len(sample) > 0 {
+				_, err = fmt.Fprint(output, "\n\n\t")
+				if err != nil {
+					return err
+				}
+				buf := bytes.NewBuffer([]byte{})
+				err = json.Indent(buf, sample, "\t", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = buf.WriteTo(output)
+				if err != nil {
+					return err
+				}
+			}
+			var statuses []string
+			for _, status := range callback.GetAcknowledge() {
+				statuses = append(statuses, fmt.Sprintf("%d %s", status, http.StatusText(status)))
+			}
+			_, err = fmt.Fprintf(output, "\n\n### Acknowledgement\n\nRespond with %s to acknowledge the callback.", strings.Join(statuses, " or "))
+			if err != nil {
+				return err
+			}
+			if callback.Retry != nil && callback.RetryResource != nil {
+				_, err = fmt.Fprintf(output, " Unacknowledged callbacks are retried up to `%s` times, `%s` apart, as set on the %s.", callback.Retry.Attempts, callback.Retry.Delay, resourceLink(resource, callback.RetryResource))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
 	default:
 		return UnsupportedOutputFormatError
 	}
-	return nil
+}
+
+// transitionName returns the name of the interaction that performs the transition.
+func transitionName(transition parse.Transition) string {
+	if transition.Resource != nil {
+		if interaction, ok := transition.Resource.GetInteraction(transition.Interaction); ok && interaction.Name != "" {
+			return interaction.Name
+		}
+	}
+	return transition.Interaction
+}
+
+// describeTimer explains what happens to the resource when the duration held by the passed property elapses.
+func describeTimer(resource *parse.Resource, property parse.Property) string {
+	switch strings.ToLower(property.Timer) {
+	case parse.TimerHide:
+		return fmt.Sprintf("The %s is not visible until `%s` has elapsed since it was created.", resource.Name, property.ID)
+	case parse.TimerLock:
+		return fmt.Sprintf("The %s's lock is released once `%s` has elapsed since it was created or last updated.", resource.Name, property.ID)
+	case parse.TimerExpire:
+		return fmt.Sprintf("The %s is deleted once `%s` has elapsed since it was created.", resource.Name, property.ID)
+	case parse.TimerRetry:
+		return fmt.Sprintf("Failed deliveries are retried once `%s` has elapsed.", property.ID)
+	}
+	return ""
+}
+
+// resourceLink links to the target resource's section of the document from the passed resource's, if they're generated in the same document.
+// Resources in other APIs are referred to by name and ID instead.
+func resourceLink(from, target *parse.Resource) string {
+	if from.API != target.API {
+		return target.Name + " (" + target.QualifiedID() + ")"
+	}
+	return "[" + target.Name + "](#" + resourceAnchor(target) + ")"
+}
+
+// resourceAnchor returns the anchor of the passed resource's header in the generated markdown, so it can be linked to.
+func resourceAnchor(resource *parse.Resource) string {
+	header := strings.ToLower(resource.Name + " (" + resource.QualifiedID() + ")")
+	anchor := ""
+	for _, c := range header {
+		switch {
+		case c == ' ':
+			anchor += "-"
+		case c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			anchor += string(c)
+		}
+	}
+	return anchor
 }
 
 func writeEndpoint(output io.Writer, outputFormat string, endpoint Endpoint) error {
@@ -96,8 +566,8 @@ func writeEndpoint(output io.Writer, outputFormat string, endpoint Endpoint) err
 	switch outputFormat {
 	case "markdown":
 		querystring := ""
-		for _, param := range endpoint.Params {
-			if param.Default != nil {
+		for _, param := range endpoint.ParamsIn(parse.ParamInQuery) {
+			if !param.IsRequired() {
 				continue
 			}
 			if querystring != "" {
@@ -116,20 +586,324 @@ func writeEndpoint(output io.Writer, outputFormat string, endpoint Endpoint) err
 		if err != nil {
 			return err
 		}
+		for _, param := range endpoint.ParamsIn(parse.ParamInHeader) {
+			_, err = fmt.Fprintf(output, "\n%s: {%s}", param.ID, param.Type)
+			if err != nil {
+				return err
+			}
+		}
+		cookies := ""
+		for _, param := range endpoint.ParamsIn(parse.ParamInCookie) {
+			if cookies != "" {
+				cookies += "; "
+			}
+			cookies += param.ID + "={" + param.Type + "}"
+		}
+		if cookies != "" {
+			_, err = fmt.Fprintf(output, "\nCookie: %s", cookies)
+			if err != nil {
+				return err
+			}
+		}
+		if len(endpoint.SampleRequest) > 0 && endpoint.Content != parse.ContentJSON {
+			_, err = fmt.Fprintf(output, "\nContent-Type: %s", endpoint.MediaType)
+			if err != nil {
+				return err
+			}
+		}
 		if len(endpoint.SampleRequest) > 0 {
-			_, err := fmt.Fprint(output, "\n\n\t")
+			err = writeSampleBody(output, endpoint.Content, endpoint.SampleRequest)
 			if err != nil {
 				return err
 			}
-      buf := bytes.NewBuffer([]byte{})
-			err = json.Indent(buf, endpoint.SampleRequest, "\t", "  ")
+		}
+		err = writeParts(output, endpoint.Parts)
+		if err != nil {
+			return err
+		}
+		described := getDescribedParams(endpoint)
+		if len(endpoint.Params) > len(described) {
+			_, err = fmt.Fprint(output, "\n\n#### Parameters\n")
 			if err != nil {
 				return err
 			}
-      _, err = buf.WriteTo(output)
-      if err != nil {
-        return err
-      }
+		}
+		for _, param := range endpoint.Params {
+			if described[param.ID] {
+				continue // pagination, filtering, sorting, and field selection are described as a whole, below
+			}
+			_, err = fmt.Fprintf(output, "\n * **%s** *(%s, %s)*: %s", param.ID, param.Type, param.Location(), param.Description)
+			if err != nil {
+				return err
+			}
+			if param.Default != nil {
+				_, err = fmt.Fprintf(output, "\n\t * **Default Value**: %v", param.Default)
+				if err != nil {
+					return err
+				}
+			}
+			if param.IsRequired() {
+				_, err = fmt.Fprint(output, "\n\t * **Required**: must be set")
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if endpoint.Pagination != nil {
+			_, err = fmt.Fprintf(output, "\n\n#### Pagination\n\n%s", describePagination(*endpoint.Pagination))
+			if err != nil {
+				return err
+			}
+		}
+		if len(endpoint.Filters) > 0 {
+			_, err = fmt.Fprint(output, "\n\n#### Filtering\n")
+			if err != nil {
+				return err
+			}
+		}
+		for _, filter := range endpoint.Filters {
+			var forms []string
+			for _, op := range filter.GetOperators() {
+				form := "`" + filter.ParamID(op) + "={value}`"
+				if op == parse.FilterIn {
+					form += " (repeated)"
+				}
+				forms = append(forms, form)
+			}
+			_, err = fmt.Fprintf(output, "\n * **%s**: %s", filter.Property, strings.Join(forms, ", "))
+			if err != nil {
+				return err
+			}
+		}
+		if len(endpoint.Sortable) > 0 {
+			_, err = fmt.Fprintf(output, "\n\n#### Sorting\n\n`%s` orders the results by one of: %s. Prefix it with `-` to reverse the order, e.g. `%s=-%s`.", parse.SortParam, strings.Join(endpoint.Sortable, ", "), parse.SortParam, endpoint.Sortable[0])
+			if err != nil {
+				return err
+			}
+		}
+		switch endpoint.Atomicity {
+		case parse.AtomicityAtomic:
+			_, err = fmt.Fprint(output, "\n\n#### Atomicity\n\nAll or nothing: if any of the resources in the request fails, none of them are changed, and the error is returned.")
+		case parse.AtomicityPerItem:
+			_, err = fmt.Fprint(output, "\n\n#### Atomicity\n\nPer item: each of the resources in the request succeeds or fails on its own. The response holds a result for each, in the order they were sent, with its `index`, its `status`, and either the resource or its errors.")
+		}
+		if err != nil {
+			return err
+		}
+		if len(endpoint.Fields) > 0 {
+			_, err = fmt.Fprintf(output, "\n\n#### Field Selection\n\n`%s` limits the response to the selected properties, and can be repeated to select more than one, e.g. `?%s=%s`. Accepted values are: %s. The representation is unchanged; properties that aren't selected are left out of it. Defaults to all properties.", parse.FieldsParam, parse.FieldsParam, endpoint.Fields[0], strings.Join(endpoint.Fields, ", "))
+			if err != nil {
+				return err
+			}
+		}
+		if len(endpoint.Security) > 0 {
+			_, err = fmt.Fprint(output, "\n\n#### Authentication\n")
+			if err != nil {
+				return err
+			}
+		}
+		for _, scheme := range endpoint.Security {
+			_, err = fmt.Fprintf(output, "\n * **%s** *(%s)*: %s", scheme.ID, scheme.Type, scheme.Description)
+			if err != nil {
+				return err
+			}
+			if scheme.Type == parse.SecurityOAuth2 && len(endpoint.Scopes) > 0 {
+				_, err = fmt.Fprintf(output, "\n\t * **Required Scopes**: %s", strings.Join(endpoint.Scopes, ", "))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return UnsupportedOutputFormatError
+	}
+	return nil
+}
+
+// getDescribedParams returns the IDs of the params that are described as part of the endpoint's pagination, filtering, sorting, or field selection, instead of individually.
+func getDescribedParams(endpoint Endpoint) map[string]bool {
+	ids := map[string]bool{}
+	if endpoint.Pagination != nil {
+		for _, param := range endpoint.Pagination.Params() {
+			ids[param.ID] = true
+		}
+	}
+	for _, filter := range endpoint.Filters {
+		for _, id := range filter.ParamIDs() {
+			ids[id] = true
+		}
+	}
+	if len(endpoint.Sortable) > 0 {
+		ids[parse.SortParam] = true
+	}
+	if len(endpoint.Fields) > 0 {
+		ids[parse.FieldsParam] = true
+	}
+	return ids
+}
+
+func describePagination(p parse.Pagination) string {
+	params := p.Params()
+	if len(params) < 2 {
+		return ""
+	}
+	size := fmt.Sprintf("`%s` sets the number of results per page", params[1].ID)
+	if p.DefaultSize != 0 {
+		size += fmt.Sprintf(", defaulting to %d", p.DefaultSize)
+	}
+	if p.MaxSize != 0 {
+		size += fmt.Sprintf(", up to a maximum of %d", p.MaxSize)
+	}
+	next := fmt.Sprintf("`%s.%s`", parse.PaginationKey, p.NextKey())
+	switch strings.ToLower(p.Style) {
+	case parse.PaginationPage:
+		return fmt.Sprintf("Results are returned a page at a time. `page` selects the 0-based page to return, and %s. Each response's %s is the page to request next; it is omitted from the last page.", size, next)
+	case parse.PaginationCursor:
+		return fmt.Sprintf("Results are returned a page at a time. Omit `cursor` to get the first page, then pass each response's %s as `cursor` to get the next page; it is omitted from the last page. %s.", next, size)
+	case parse.PaginationOffset:
+		return fmt.Sprintf("Results are returned a page at a time. `offset` sets the number of results to skip, and %s. Each response's %s is the offset to request next; it is omitted from the last page.", size, next)
+	}
+	return ""
+}
+
+// writeSampleBody writes a sample request or response body as an indented block. JSON bodies are pretty-printed; other bodies are written as they are.
+func writeSampleBody(output io.Writer, content string, sample []byte) error {
+	_, err := fmt.Fprint(output, "\n\n\t")
+	if err != nil {
+		return err
+	}
+	if content != parse.ContentJSON {
+		_, err = fmt.Fprint(output, strings.Replace(strings.TrimRight(string(sample), "\n"), "\n", "\n\t", -1))
+		return err
+	}
+	buf := bytes.NewBuffer([]byte{})
+	err = json.Indent(buf, sample, "\t", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(output)
+	return err
+}
+
+// writeParts writes the named parts of a multipart request body.
+func writeParts(output io.Writer, parts []parse.Part) error {
+	if len(parts) < 1 {
+		return nil
+	}
+	_, err := fmt.Fprint(output, "\n\n#### Parts\n")
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		_, err = fmt.Fprintf(output, "\n * **%s** *(%s)*: %s", part.Name, part.GetMediaType(), part.Description)
+		if err != nil {
+			return err
+		}
+		if part.GetContent() == parse.ContentJSON {
+			_, err = fmt.Fprint(output, "\n\t * **Holds**: the resource, as it would be sent in a JSON request body")
+			if err != nil {
+				return err
+			}
+		}
+		if part.Optional {
+			_, err = fmt.Fprint(output, "\n\t * **Optional**: can be left out of the request")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeEndpointResponse(output io.Writer, outputFormat string, endpoint Endpoint) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		_, err := fmt.Fprintf(output, "\n\n### Response\n\n%d %s", endpoint.Response.Status, http.StatusText(endpoint.Response.Status))
+		if err != nil {
+			return err
+		}
+		if len(endpoint.Response.Headers) > 0 {
+			_, err = fmt.Fprint(output, "\n")
+			if err != nil {
+				return err
+			}
+		}
+		for _, header := range endpoint.Response.Headers {
+			_, err = fmt.Fprintf(output, "\n * **%s** *(%s)*: %s", header.ID, header.Type, header.Description)
+			if err != nil {
+				return err
+			}
+		}
+		if len(endpoint.SampleResponse) > 0 {
+			if endpoint.Response.GetContent() != parse.ContentJSON {
+				_, err = fmt.Fprintf(output, "\n\nContent-Type: %s", endpoint.Response.GetMediaType())
+				if err != nil {
+					return err
+				}
+			}
+			err = writeSampleBody(output, endpoint.Response.GetContent(), endpoint.SampleResponse)
+			if err != nil {
+				return err
+			}
+		}
+		if !endpoint.NotModified {
+			return nil
+		}
+		_, err = fmt.Fprintf(output, "\n\n%d %s\n\nReturned without a body when the %s header matches the current %s.", http.StatusNotModified, http.StatusText(http.StatusNotModified), parse.IfNoneMatchHeader, parse.ETagHeader)
+		if err != nil {
+			return err
+		}
+	default:
+		return UnsupportedOutputFormatError
+	}
+	return nil
+}
+
+func writeEndpointErrors(output io.Writer, outputFormat string, endpoint Endpoint) error {
+	outputFormat = strings.ToLower(outputFormat)
+	switch outputFormat {
+	case "markdown":
+		if len(endpoint.Errors) < 1 {
+			return nil
+		}
+		_, err := fmt.Fprint(output, "\n\n### Errors\n")
+		if err != nil {
+			return err
+		}
+		for _, e := range endpoint.Errors {
+			_, err = fmt.Fprintf(output, "\n * **%s** *(%d)*: %s", e.Code, e.Status, e.Description)
+			if err != nil {
+				return err
+			}
+			if e.Field != "" {
+				_, err = fmt.Fprintf(output, "\n\t * **Field**: %s", e.Field)
+				if err != nil {
+					return err
+				}
+			}
+			if e.Action != "" {
+				_, err = fmt.Fprintf(output, "\n\t * **Resolution**: %s", e.Action)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(endpoint.SampleError) < 1 {
+			return nil
+		}
+		_, err = fmt.Fprintf(output, "\n\nErrors are returned as `%s`:\n\n\t", ErrorContentType(endpoint.ErrorFormat))
+		if err != nil {
+			return err
+		}
+		buf := bytes.NewBuffer([]byte{})
+		err = json.Indent(buf, endpoint.SampleError, "\t", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = buf.WriteTo(output)
+		if err != nil {
+			return err
 		}
 	default:
 		return UnsupportedOutputFormatError